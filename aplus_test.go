@@ -0,0 +1,611 @@
+package vowlink
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 本文件移植自 Promises/A+ 规范测试套件（https://github.com/promises-aplus/promises-tests），
+// 测试名称中的编号与规范中的条款一一对应。
+//
+// 与规范的差异：
+//   - 2.2.5：Go 中没有 this，不适用。
+//   - 2.2.7.2：回调通过返回错误或 panic 代替抛出异常。
+
+// 对应测试套件中 adapter.deferred()
+func aplusDeferred() (*Promise, func(interface{}), func(error)) {
+	var resolveFn, rejectFn func(interface{}, error)
+	promise := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+		resolveFn = resolve
+		rejectFn = reject
+	})
+	return promise, func(value interface{}) { resolveFn(value, nil) }, func(reason error) { rejectFn(nil, reason) }
+}
+
+// 对应测试套件中 adapter.resolved()
+func aplusResolved(value interface{}) *Promise {
+	promise, resolve, _ := aplusDeferred()
+	resolve(value)
+	return promise
+}
+
+// 对应测试套件中 adapter.rejected()
+func aplusRejected(reason error) *Promise {
+	promise, _, reject := aplusDeferred()
+	reject(reason)
+	return promise
+}
+
+// 等待 Promise 结束，超时则使测试失败
+func aplusWait(t *testing.T, promise *Promise) (interface{}, error) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	value, reason := promise.Await(ctx)
+	if ctx.Err() != nil {
+		t.Fatal("Expected promise to settle")
+	}
+	return value, reason
+}
+
+// 确认 Promise 在一段时间内保持 Pending 状态
+func aplusStaysPending(t *testing.T, promise *Promise) {
+	t.Helper()
+
+	select {
+	case <-promise.Done():
+		t.Fatal("Expected promise to stay pending")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// 分别以已完成、注册回调的同时完成、注册回调后延迟完成三种方式运行测试
+func testFulfilled(t *testing.T, value interface{}, test func(t *testing.T, promise *Promise)) {
+	t.Run("already-fulfilled", func(t *testing.T) {
+		test(t, aplusResolved(value))
+	})
+	t.Run("immediately-fulfilled", func(t *testing.T) {
+		promise, resolve, _ := aplusDeferred()
+		go resolve(value)
+		test(t, promise)
+	})
+	t.Run("eventually-fulfilled", func(t *testing.T) {
+		promise, resolve, _ := aplusDeferred()
+		time.AfterFunc(20*time.Millisecond, func() { resolve(value) })
+		test(t, promise)
+	})
+}
+
+// 分别以已拒绝、注册回调的同时拒绝、注册回调后延迟拒绝三种方式运行测试
+func testRejected(t *testing.T, reason error, test func(t *testing.T, promise *Promise)) {
+	t.Run("already-rejected", func(t *testing.T) {
+		test(t, aplusRejected(reason))
+	})
+	t.Run("immediately-rejected", func(t *testing.T) {
+		promise, _, reject := aplusDeferred()
+		go reject(reason)
+		test(t, promise)
+	})
+	t.Run("eventually-rejected", func(t *testing.T) {
+		promise, _, reject := aplusDeferred()
+		time.AfterFunc(20*time.Millisecond, func() { reject(reason) })
+		test(t, promise)
+	})
+}
+
+// thenableFunc 将函数适配为 Thenable，用于 2.3.3 的测试
+type thenableFunc func(resolvePromise func(interface{}), rejectPromise func(error))
+
+func (f thenableFunc) Then(onFulfilled func(interface{}), onRejected func(error)) {
+	f(onFulfilled, onRejected)
+}
+
+var (
+	aplusDummy    = struct{ dummy string }{"dummy"}
+	aplusSentinel = struct{ sentinel string }{"sentinel"}
+	aplusReason   = errors.New("reason")
+	aplusOther    = errors.New("other reason")
+)
+
+func TestAPlus_2_1_States(t *testing.T) {
+	t.Run("2.1.2.1 When fulfilled, a promise must not transition to any other state", func(t *testing.T) {
+		promise, resolve, reject := aplusDeferred()
+		resolve(aplusDummy)
+		reject(aplusReason)
+		resolve(aplusSentinel)
+
+		value, reason := aplusWait(t, promise)
+		assert.Equal(t, Fulfilled, promise.getState(), "Expected state to be Fulfilled")
+		assert.Equal(t, aplusDummy, value, "Expected value to be unchanged")
+		assert.Nil(t, reason, "Expected reason to be nil")
+	})
+
+	t.Run("2.1.3.1 When rejected, a promise must not transition to any other state", func(t *testing.T) {
+		promise, resolve, reject := aplusDeferred()
+		reject(aplusReason)
+		resolve(aplusDummy)
+		reject(aplusOther)
+
+		value, reason := aplusWait(t, promise)
+		assert.Equal(t, Rejected, promise.getState(), "Expected state to be Rejected")
+		assert.Nil(t, value, "Expected value to be nil")
+		assert.Equal(t, aplusReason, reason, "Expected reason to be unchanged")
+	})
+
+	t.Run("2.1.2.1 Trying to fulfill then reject with a delay", func(t *testing.T) {
+		promise, resolve, reject := aplusDeferred()
+		go func() {
+			resolve(aplusDummy)
+			time.Sleep(20 * time.Millisecond)
+			reject(aplusReason)
+		}()
+
+		value, _ := aplusWait(t, promise)
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, aplusDummy, value, "Expected value to be 'dummy'")
+		assert.Equal(t, Fulfilled, promise.getState(), "Expected state to stay Fulfilled")
+	})
+}
+
+func TestAPlus_2_2_Then(t *testing.T) {
+	t.Run("2.2.1 Both onFulfilled and onRejected are optional arguments", func(t *testing.T) {
+		value, _ := aplusWait(t, aplusResolved(aplusDummy).Then(nil, nil))
+		assert.Equal(t, aplusDummy, value, "Expected nil onFulfilled to be ignored")
+
+		_, reason := aplusWait(t, aplusRejected(aplusReason).Then(nil, nil))
+		assert.Equal(t, aplusReason, reason, "Expected nil onRejected to be ignored")
+	})
+
+	t.Run("2.2.2.1 onFulfilled must be called after promise is fulfilled, with promise's value", func(t *testing.T) {
+		testFulfilled(t, aplusSentinel, func(t *testing.T, promise *Promise) {
+			called := make(chan interface{}, 1)
+			promise.Then(func(value interface{}) (interface{}, error) {
+				called <- value
+				return nil, nil
+			}, nil)
+
+			aplusWait(t, promise)
+			assert.Equal(t, aplusSentinel, <-called, "Expected onFulfilled to receive the value")
+		})
+	})
+
+	t.Run("2.2.2.2 onFulfilled must not be called before promise is fulfilled", func(t *testing.T) {
+		promise, resolve, _ := aplusDeferred()
+		var called int32
+		promise2 := promise.Then(func(value interface{}) (interface{}, error) {
+			atomic.StoreInt32(&called, 1)
+			return nil, nil
+		}, nil)
+
+		aplusStaysPending(t, promise)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&called), "Expected onFulfilled not to be called yet")
+
+		resolve(aplusDummy)
+		aplusWait(t, promise2)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&called), "Expected onFulfilled to be called")
+	})
+
+	t.Run("2.2.2.3 onFulfilled must not be called more than once", func(t *testing.T) {
+		promise, resolve, reject := aplusDeferred()
+		var calls int32
+		promise2 := promise.Then(func(value interface{}) (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			return nil, nil
+		}, nil)
+
+		resolve(aplusDummy)
+		resolve(aplusDummy)
+		reject(aplusReason)
+
+		aplusWait(t, promise2)
+		time.Sleep(20 * time.Millisecond)
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Expected onFulfilled to be called once")
+	})
+
+	t.Run("2.2.3.1 onRejected must be called after promise is rejected, with promise's reason", func(t *testing.T) {
+		testRejected(t, aplusReason, func(t *testing.T, promise *Promise) {
+			called := make(chan error, 1)
+			promise.Then(nil, func(reason error) (interface{}, error) {
+				called <- reason
+				return nil, nil
+			})
+
+			aplusWait(t, promise)
+			assert.Equal(t, aplusReason, <-called, "Expected onRejected to receive the reason")
+		})
+	})
+
+	t.Run("2.2.3.2 onRejected must not be called before promise is rejected", func(t *testing.T) {
+		promise, _, reject := aplusDeferred()
+		var called int32
+		promise2 := promise.Then(nil, func(reason error) (interface{}, error) {
+			atomic.StoreInt32(&called, 1)
+			return nil, nil
+		})
+
+		aplusStaysPending(t, promise)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&called), "Expected onRejected not to be called yet")
+
+		reject(aplusReason)
+		aplusWait(t, promise2)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&called), "Expected onRejected to be called")
+	})
+
+	t.Run("2.2.3.3 onRejected must not be called more than once", func(t *testing.T) {
+		promise, resolve, reject := aplusDeferred()
+		var calls int32
+		promise2 := promise.Then(nil, func(reason error) (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			return nil, nil
+		})
+
+		reject(aplusReason)
+		reject(aplusReason)
+		resolve(aplusDummy)
+
+		aplusWait(t, promise2)
+		time.Sleep(20 * time.Millisecond)
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Expected onRejected to be called once")
+	})
+
+	t.Run("2.2.4 onFulfilled must not be called until then returns", func(t *testing.T) {
+		testFulfilled(t, aplusDummy, func(t *testing.T, promise *Promise) {
+			returned := make(chan struct{})
+			promise2 := promise.Then(func(value interface{}) (interface{}, error) {
+				select {
+				case <-returned:
+					return true, nil
+				case <-time.After(time.Second):
+					return false, nil
+				}
+			}, nil)
+			close(returned)
+
+			value, _ := aplusWait(t, promise2)
+			assert.Equal(t, true, value, "Expected onFulfilled to run after then returned")
+		})
+	})
+
+	t.Run("2.2.4 onRejected must not be called until then returns", func(t *testing.T) {
+		testRejected(t, aplusReason, func(t *testing.T, promise *Promise) {
+			returned := make(chan struct{})
+			promise2 := promise.Then(nil, func(reason error) (interface{}, error) {
+				select {
+				case <-returned:
+					return true, nil
+				case <-time.After(time.Second):
+					return false, nil
+				}
+			})
+			close(returned)
+
+			value, _ := aplusWait(t, promise2)
+			assert.Equal(t, true, value, "Expected onRejected to run after then returned")
+		})
+	})
+
+	t.Run("2.2.4 Resolving inside a handler does not run other handlers before it returns", func(t *testing.T) {
+		promise, resolve, _ := aplusDeferred()
+		var handled int32
+		promise2 := promise.Then(func(value interface{}) (interface{}, error) {
+			atomic.StoreInt32(&handled, 1)
+			return nil, nil
+		}, nil)
+
+		var seen int32
+		promise3 := aplusResolved(aplusDummy).Then(func(value interface{}) (interface{}, error) {
+			resolve(aplusDummy)
+			atomic.StoreInt32(&seen, atomic.LoadInt32(&handled))
+			return nil, nil
+		}, nil)
+
+		aplusWait(t, promise2)
+		aplusWait(t, promise3)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&seen), "Expected the other handler to run after the current one returned")
+	})
+
+	t.Run("2.2.6.1 Multiple fulfillment handlers are called in the order of their originating calls", func(t *testing.T) {
+		testFulfilled(t, aplusSentinel, func(t *testing.T, promise *Promise) {
+			var mu sync.Mutex
+			var order []int
+			handler := func(i int) func(interface{}) (interface{}, error) {
+				return func(value interface{}) (interface{}, error) {
+					mu.Lock()
+					order = append(order, i)
+					mu.Unlock()
+					if i == 2 {
+						panic("handler failed")
+					}
+					return i, nil
+				}
+			}
+
+			results := []*Promise{promise.Then(handler(1), nil), promise.Then(handler(2), nil), promise.Then(handler(3), nil)}
+			for _, result := range results {
+				aplusWait(t, result)
+			}
+
+			assert.Equal(t, []int{1, 2, 3}, order, "Expected handlers to run in registration order")
+			assert.Equal(t, 1, results[0].GetValue(), "Expected first result to be 1")
+			assert.IsType(t, &PanicError{}, results[1].GetReason(), "Expected panicking handler to reject only its own promise")
+			assert.Equal(t, 3, results[2].GetValue(), "Expected third result to be 3")
+		})
+	})
+
+	t.Run("2.2.6.2 Multiple rejection handlers are called in the order of their originating calls", func(t *testing.T) {
+		testRejected(t, aplusReason, func(t *testing.T, promise *Promise) {
+			var mu sync.Mutex
+			var order []int
+			handler := func(i int) func(error) (interface{}, error) {
+				return func(reason error) (interface{}, error) {
+					mu.Lock()
+					order = append(order, i)
+					mu.Unlock()
+					if i == 2 {
+						return nil, aplusOther
+					}
+					return i, nil
+				}
+			}
+
+			results := []*Promise{promise.Then(nil, handler(1)), promise.Then(nil, handler(2)), promise.Then(nil, handler(3))}
+			for _, result := range results {
+				aplusWait(t, result)
+			}
+
+			assert.Equal(t, []int{1, 2, 3}, order, "Expected handlers to run in registration order")
+			assert.Equal(t, 1, results[0].GetValue(), "Expected first result to be 1")
+			assert.Equal(t, aplusOther, results[1].GetReason(), "Expected failing handler to reject only its own promise")
+			assert.Equal(t, 3, results[2].GetValue(), "Expected third result to be 3")
+		})
+	})
+
+	t.Run("2.2.6 Handlers added inside a handler run after the existing ones", func(t *testing.T) {
+		promise := aplusResolved(aplusDummy)
+		var mu sync.Mutex
+		var order []int
+		record := func(i int) {
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		}
+
+		var promise3 *Promise
+		promise2 := promise.Then(func(value interface{}) (interface{}, error) {
+			record(1)
+			promise3 = promise.Then(func(value interface{}) (interface{}, error) {
+				record(3)
+				return nil, nil
+			}, nil)
+			return nil, nil
+		}, nil)
+		promise4 := promise.Then(func(value interface{}) (interface{}, error) {
+			record(2)
+			return nil, nil
+		}, nil)
+
+		aplusWait(t, promise2)
+		aplusWait(t, promise4)
+		aplusWait(t, promise3)
+		assert.Equal(t, []int{1, 2, 3}, order, "Expected the nested handler to run last")
+	})
+
+	t.Run("2.2.7 then must return a promise", func(t *testing.T) {
+		promise, _, _ := aplusDeferred()
+		assert.NotNil(t, promise.Then(nil, nil), "Expected then to return a promise")
+	})
+
+	t.Run("2.2.7.1 onFulfilled returning a value fulfills promise2", func(t *testing.T) {
+		testFulfilled(t, aplusDummy, func(t *testing.T, promise *Promise) {
+			value, reason := aplusWait(t, promise.Then(func(value interface{}) (interface{}, error) {
+				return aplusSentinel, nil
+			}, nil))
+			assert.Equal(t, aplusSentinel, value, "Expected promise2 to be fulfilled with the returned value")
+			assert.Nil(t, reason, "Expected reason to be nil")
+		})
+	})
+
+	t.Run("2.2.7.1 onRejected returning a value fulfills promise2", func(t *testing.T) {
+		testRejected(t, aplusReason, func(t *testing.T, promise *Promise) {
+			promise2 := promise.Then(nil, func(reason error) (interface{}, error) {
+				return aplusSentinel, nil
+			})
+			value, reason := aplusWait(t, promise2)
+			assert.Equal(t, Fulfilled, promise2.getState(), "Expected promise2 to be Fulfilled")
+			assert.Equal(t, aplusSentinel, value, "Expected promise2 to be fulfilled with the returned value")
+			assert.Nil(t, reason, "Expected reason to be nil")
+		})
+	})
+
+	t.Run("2.2.7.2 onFulfilled returning an error rejects promise2", func(t *testing.T) {
+		testFulfilled(t, aplusDummy, func(t *testing.T, promise *Promise) {
+			promise2 := promise.Then(func(value interface{}) (interface{}, error) {
+				return nil, aplusOther
+			}, nil)
+			_, reason := aplusWait(t, promise2)
+			assert.Equal(t, Rejected, promise2.getState(), "Expected promise2 to be Rejected")
+			assert.Equal(t, aplusOther, reason, "Expected promise2 to be rejected with the returned error")
+		})
+	})
+
+	t.Run("2.2.7.2 onRejected panicking rejects promise2", func(t *testing.T) {
+		testRejected(t, aplusReason, func(t *testing.T, promise *Promise) {
+			promise2 := promise.Then(nil, func(reason error) (interface{}, error) {
+				panic(aplusOther)
+			})
+			_, reason := aplusWait(t, promise2)
+			assert.Equal(t, Rejected, promise2.getState(), "Expected promise2 to be Rejected")
+			assert.True(t, errors.Is(reason, aplusOther), "Expected promise2 to be rejected with the panic value")
+		})
+	})
+
+	t.Run("2.2.7.3 onFulfilled is not a function, promise2 is fulfilled with the same value", func(t *testing.T) {
+		testFulfilled(t, aplusSentinel, func(t *testing.T, promise *Promise) {
+			value, reason := aplusWait(t, promise.Then(nil, func(reason error) (interface{}, error) {
+				return aplusDummy, nil
+			}))
+			assert.Equal(t, aplusSentinel, value, "Expected the value to pass through")
+			assert.Nil(t, reason, "Expected reason to be nil")
+		})
+	})
+
+	t.Run("2.2.7.4 onRejected is not a function, promise2 is rejected with the same reason", func(t *testing.T) {
+		testRejected(t, aplusReason, func(t *testing.T, promise *Promise) {
+			_, reason := aplusWait(t, promise.Then(func(value interface{}) (interface{}, error) {
+				return aplusDummy, nil
+			}, nil))
+			assert.Equal(t, aplusReason, reason, "Expected the reason to pass through")
+		})
+	})
+}
+
+func TestAPlus_2_3_ResolutionProcedure(t *testing.T) {
+	t.Run("2.3.1 promise and x refer to the same object", func(t *testing.T) {
+		var promise2 *Promise
+		promise2 = aplusResolved(aplusDummy).Then(func(value interface{}) (interface{}, error) {
+			return promise2, nil
+		}, nil)
+
+		_, reason := aplusWait(t, promise2)
+		assert.Equal(t, ErrPromiseCycle, reason, "Expected promise2 to be rejected with ErrPromiseCycle")
+	})
+
+	t.Run("2.3.2.1 x is pending, promise remains pending until x is fulfilled or rejected", func(t *testing.T) {
+		x, resolveX, _ := aplusDeferred()
+		promise := aplusResolved(aplusDummy).Then(func(value interface{}) (interface{}, error) {
+			return x, nil
+		}, nil)
+
+		aplusStaysPending(t, promise)
+
+		resolveX(aplusSentinel)
+		value, _ := aplusWait(t, promise)
+		assert.Equal(t, aplusSentinel, value, "Expected promise to adopt the value of x")
+	})
+
+	t.Run("2.3.2.2 x is fulfilled, promise is fulfilled with the same value", func(t *testing.T) {
+		testFulfilled(t, aplusSentinel, func(t *testing.T, x *Promise) {
+			value, reason := aplusWait(t, aplusResolved(aplusDummy).Then(func(value interface{}) (interface{}, error) {
+				return x, nil
+			}, nil))
+			assert.Equal(t, aplusSentinel, value, "Expected promise to adopt the value of x")
+			assert.Nil(t, reason, "Expected reason to be nil")
+		})
+	})
+
+	t.Run("2.3.2.3 x is rejected, promise is rejected with the same reason", func(t *testing.T) {
+		testRejected(t, aplusReason, func(t *testing.T, x *Promise) {
+			_, reason := aplusWait(t, aplusResolved(aplusDummy).Then(func(value interface{}) (interface{}, error) {
+				return x, nil
+			}, nil))
+			assert.Equal(t, aplusReason, reason, "Expected promise to adopt the reason of x")
+		})
+	})
+
+	resolveWithThenable := func(x Thenable) *Promise {
+		return aplusResolved(aplusDummy).Then(func(value interface{}) (interface{}, error) {
+			return x, nil
+		}, nil)
+	}
+
+	t.Run("2.3.3.3.1 resolvePromise called with y runs [[Resolve]](promise, y)", func(t *testing.T) {
+		nested := thenableFunc(func(resolvePromise func(interface{}), rejectPromise func(error)) {
+			resolvePromise(aplusResolved(aplusSentinel))
+		})
+		value, reason := aplusWait(t, resolveWithThenable(thenableFunc(func(resolvePromise func(interface{}), rejectPromise func(error)) {
+			resolvePromise(nested)
+		})))
+		assert.Equal(t, aplusSentinel, value, "Expected nested thenables to be resolved")
+		assert.Nil(t, reason, "Expected reason to be nil")
+	})
+
+	t.Run("2.3.3.3.1 resolvePromise called asynchronously", func(t *testing.T) {
+		value, _ := aplusWait(t, resolveWithThenable(thenableFunc(func(resolvePromise func(interface{}), rejectPromise func(error)) {
+			time.AfterFunc(20*time.Millisecond, func() { resolvePromise(aplusSentinel) })
+		})))
+		assert.Equal(t, aplusSentinel, value, "Expected promise to be fulfilled with y")
+	})
+
+	t.Run("2.3.3.3.2 rejectPromise called with r rejects promise with r", func(t *testing.T) {
+		_, reason := aplusWait(t, resolveWithThenable(thenableFunc(func(resolvePromise func(interface{}), rejectPromise func(error)) {
+			rejectPromise(aplusReason)
+		})))
+		assert.Equal(t, aplusReason, reason, "Expected promise to be rejected with r")
+	})
+
+	t.Run("2.3.3.3.3 Only the first call of resolvePromise and rejectPromise takes effect", func(t *testing.T) {
+		value, _ := aplusWait(t, resolveWithThenable(thenableFunc(func(resolvePromise func(interface{}), rejectPromise func(error)) {
+			resolvePromise(aplusSentinel)
+			rejectPromise(aplusReason)
+			resolvePromise(aplusDummy)
+		})))
+		assert.Equal(t, aplusSentinel, value, "Expected the first call to win")
+
+		_, reason := aplusWait(t, resolveWithThenable(thenableFunc(func(resolvePromise func(interface{}), rejectPromise func(error)) {
+			rejectPromise(aplusReason)
+			resolvePromise(aplusSentinel)
+			rejectPromise(aplusOther)
+		})))
+		assert.Equal(t, aplusReason, reason, "Expected the first call to win")
+	})
+
+	t.Run("2.3.3.3.4.1 then panicking after resolvePromise is called is ignored", func(t *testing.T) {
+		value, reason := aplusWait(t, resolveWithThenable(thenableFunc(func(resolvePromise func(interface{}), rejectPromise func(error)) {
+			resolvePromise(aplusSentinel)
+			panic(aplusOther)
+		})))
+		assert.Equal(t, aplusSentinel, value, "Expected the panic to be ignored")
+		assert.Nil(t, reason, "Expected reason to be nil")
+	})
+
+	t.Run("2.3.3.3.4.2 then panicking before resolvePromise is called rejects promise", func(t *testing.T) {
+		_, reason := aplusWait(t, resolveWithThenable(thenableFunc(func(resolvePromise func(interface{}), rejectPromise func(error)) {
+			panic(aplusOther)
+		})))
+		assert.IsType(t, &PanicError{}, reason, "Expected promise to be rejected with a PanicError")
+		assert.True(t, errors.Is(reason, aplusOther), "Expected the panic value to be unwrapped")
+	})
+
+	t.Run("2.3.4 x is not a thenable, promise is fulfilled with x", func(t *testing.T) {
+		var nilPromise *Promise
+		for _, x := range []interface{}{nil, 0, "", false, aplusDummy, aplusReason, nilPromise} {
+			promise := aplusResolved(aplusDummy).Then(func(value interface{}) (interface{}, error) {
+				return x, nil
+			}, nil)
+			value, reason := aplusWait(t, promise)
+			assert.Equal(t, Fulfilled, promise.getState(), "Expected state to be Fulfilled")
+			assert.Equal(t, x, value, "Expected promise to be fulfilled with x")
+			assert.Nil(t, reason, "Expected reason to be nil")
+		}
+	})
+}
+
+func TestAPlus_ResolveWithReason(t *testing.T) {
+	var resolveFn func(interface{}, error)
+	promise := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+		resolveFn = resolve
+	})
+	var fulfilled int32
+	result := promise.Then(func(value interface{}) (interface{}, error) {
+		atomic.StoreInt32(&fulfilled, 1)
+		return value, nil
+	}, nil)
+
+	resolveFn(aplusDummy, aplusReason)
+	aplusWait(t, result)
+
+	assert.Equal(t, Rejected, promise.getState(), "Expected resolve with a reason to reject the promise")
+	assert.Equal(t, aplusReason, promise.GetReason(), "Expected reason to be kept")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&fulfilled), "Expected onFulfilled not to be called")
+	assert.Equal(t, aplusReason, result.GetReason(), "Expected the reason to pass through")
+}
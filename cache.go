@@ -0,0 +1,223 @@
+package vowlink
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// CacheOption 配置 PromiseCache 的行为
+type CacheOption func(*cacheConfig)
+
+type cacheConfig struct {
+	ttl        time.Duration
+	errorTTL   time.Duration
+	staleTTL   time.Duration
+	maxEntries int
+	now        func() time.Time
+}
+
+// 默认完成的结果永不过期，拒绝的结果不缓存，不限制条目数量
+func newCacheConfig() *cacheConfig {
+	return &cacheConfig{now: time.Now}
+}
+
+// WithCacheTTL 设置完成的结果的缓存时间，小于等于 0 时永不过期
+func WithCacheTTL(ttl time.Duration) CacheOption {
+	return func(c *cacheConfig) {
+		c.ttl = ttl
+	}
+}
+
+// WithCacheErrorTTL 设置被拒绝或被取消的结果的缓存时间，小于等于 0 时不缓存，结束后立即移除
+func WithCacheErrorTTL(ttl time.Duration) CacheOption {
+	return func(c *cacheConfig) {
+		c.errorTTL = ttl
+	}
+}
+
+// WithCacheMaxEntries 设置最大条目数量，超出时移除最久未使用的条目，小于等于 0 时不限制
+func WithCacheMaxEntries(maxEntries int) CacheOption {
+	return func(c *cacheConfig) {
+		c.maxEntries = maxEntries
+	}
+}
+
+// WithStaleWhileRevalidate 设置完成的结果过期后仍可使用的时间
+// 在这段时间内获取过期的条目会直接返回旧的结果，同时在后台调用工厂函数刷新
+func WithStaleWhileRevalidate(stale time.Duration) CacheOption {
+	return func(c *cacheConfig) {
+		c.staleTTL = stale
+	}
+}
+
+// WithCacheClock 设置获取当前时间的函数，用于计算过期时间
+func WithCacheClock(now func() time.Time) CacheOption {
+	return func(c *cacheConfig) {
+		if now != nil {
+			c.now = now
+		}
+	}
+}
+
+// 缓存条目，promise 结束前 settled 为 false，此时不会过期
+type cacheEntry struct {
+	key        string
+	promise    *Promise
+	settled    bool
+	expires    time.Time // 零值表示永不过期
+	refreshing bool
+	element    *list.Element
+}
+
+// PromiseCache 按 key 缓存 Promise 的结果
+// 进行中的 Promise 会被所有调用者共享，结束后按照其状态决定缓存时间
+// PromiseCache 需要通过 NewPromiseCache 创建
+type PromiseCache struct {
+	mu      sync.Mutex
+	config  *cacheConfig
+	entries map[string]*cacheEntry
+	lru     *list.List // 最近使用的条目在前
+}
+
+// NewPromiseCache 创建新的 PromiseCache
+func NewPromiseCache(opts ...CacheOption) *PromiseCache {
+	config := newCacheConfig()
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return &PromiseCache{
+		config:  config,
+		entries: make(map[string]*cacheEntry),
+		lru:     list.New(),
+	}
+}
+
+// Get 返回 key 对应的 Promise，没有可用的条目时调用 factory 创建并缓存
+// factory 返回 nil 时 Promise 以 ErrNilPromise 被拒绝，发生 panic 时以 PanicError 被拒绝
+// 返回的 Promise 被所有调用者共享，取消它会影响所有调用者
+func (c *PromiseCache) Get(key string, factory func() *Promise) *Promise {
+	if factory == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	now := c.config.now()
+	if entry, ok := c.entries[key]; ok {
+		switch {
+		case !entry.settled || entry.expires.IsZero() || now.Before(entry.expires):
+			c.lru.MoveToFront(entry.element)
+			c.mu.Unlock()
+			return entry.promise
+
+		case c.isStale(entry, now):
+			c.lru.MoveToFront(entry.element)
+			promise := entry.promise
+			refresh := !entry.refreshing
+			entry.refreshing = true
+			c.mu.Unlock()
+
+			if refresh {
+				go c.refresh(entry, factory)
+			}
+			return promise
+		}
+
+		c.remove(entry)
+	}
+
+	entry := &cacheEntry{key: key, promise: &Promise{state: Pending}}
+	entry.element = c.lru.PushFront(entry)
+	c.entries[key] = entry
+	if c.config.maxEntries > 0 && c.lru.Len() > c.config.maxEntries {
+		c.remove(c.lru.Back().Value.(*cacheEntry))
+	}
+	c.mu.Unlock()
+
+	// 缓存的记录不会消费拒绝原因，没有调用者处理的拒绝仍会被报告为未处理
+	call := entry.promise
+	call.listen(func() {
+		c.settle(entry, call)
+	})
+	call.resolve(callFactory(factory), nil)
+
+	return call
+}
+
+// Delete 移除 key 对应的条目，已经返回给调用者的 Promise 不受影响
+func (c *PromiseCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[key]; ok {
+		c.remove(entry)
+	}
+}
+
+// Len 返回缓存中的条目数量，包括进行中和已过期但尚未被移除的条目
+func (c *PromiseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// 判断已过期的条目是否仍处于可以使用旧结果的时间内，只有完成的结果可以在过期后使用
+func (c *PromiseCache) isStale(entry *cacheEntry, now time.Time) bool {
+	return c.config.staleTTL > 0 && entry.promise.getState() == Fulfilled && now.Before(entry.expires.Add(c.config.staleTTL))
+}
+
+// 在条目的 Promise 结束时计算过期时间，拒绝的结果不需要缓存时移除条目
+func (c *PromiseCache) settle(entry *cacheEntry, promise *Promise) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries[entry.key] != entry || entry.promise != promise {
+		return
+	}
+
+	ttl := c.config.ttl
+	if promise.getState() != Fulfilled {
+		if c.config.errorTTL <= 0 {
+			c.remove(entry)
+			return
+		}
+		ttl = c.config.errorTTL
+	}
+
+	entry.settled = true
+	if ttl > 0 {
+		entry.expires = c.config.now().Add(ttl)
+	}
+}
+
+// 调用 factory 刷新过期的条目，成功时替换条目的 Promise，失败时继续使用旧的结果
+// 在独立的 goroutine 中执行，不会阻塞获取旧结果的调用者
+// 刷新失败的原因由缓存消费，不会被报告为未处理的拒绝
+func (c *PromiseCache) refresh(entry *cacheEntry, factory func() *Promise) {
+	next := callFactory(factory)
+	next.subscribe(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		entry.refreshing = false
+		if c.entries[entry.key] != entry || next.getState() != Fulfilled {
+			return
+		}
+
+		entry.promise = next
+		entry.expires = time.Time{}
+		if c.config.ttl > 0 {
+			entry.expires = c.config.now().Add(c.config.ttl)
+		}
+	})
+}
+
+// 从缓存中移除条目，调用者需要持有锁
+func (c *PromiseCache) remove(entry *cacheEntry) {
+	c.lru.Remove(entry.element)
+	if c.entries[entry.key] == entry {
+		delete(c.entries, entry.key)
+	}
+}
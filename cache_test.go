@@ -0,0 +1,256 @@
+package vowlink
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testClock 是一个可以手动推进的时钟
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (tc *testClock) Now() time.Time {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	return tc.now
+}
+
+func (tc *testClock) Advance(d time.Duration) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.now = tc.now.Add(d)
+}
+
+// 创建一个记录调用次数的 Promise 工厂函数，第 n 次调用在 fail(n) 返回 true 时被拒绝，否则以 n 完成
+func makeCacheFactory(calls *int32, fail func(n int32) bool) func() *Promise {
+	return func() *Promise {
+		n := atomic.AddInt32(calls, 1)
+		return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			if fail != nil && fail(n) {
+				reject(nil, fmt.Errorf("attempt %d failed", n))
+			} else {
+				resolve(n, nil)
+			}
+		})
+	}
+}
+
+// 等待 key 对应条目的后台刷新结束
+func waitCacheRefreshed(t *testing.T, cache *PromiseCache, key string) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		cache.mu.Lock()
+		entry, ok := cache.entries[key]
+		refreshing := ok && entry.refreshing
+		cache.mu.Unlock()
+		if !refreshing {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("Expected the refresh to finish")
+}
+
+func TestPromiseCache_Get(t *testing.T) {
+	t.Run("Fulfilled value is cached until TTL", func(t *testing.T) {
+		clock := newTestClock()
+		cache := NewPromiseCache(WithCacheTTL(time.Minute), WithCacheClock(clock.Now))
+		var calls int32
+		factory := makeCacheFactory(&calls, nil)
+
+		first := cache.Get("key", factory)
+		clock.Advance(30 * time.Second)
+		second := cache.Get("key", factory)
+
+		assert.Same(t, first, second, "Expected the cached Promise before TTL")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Expected factory to be called once")
+
+		clock.Advance(30 * time.Second)
+		third := cache.Get("key", factory)
+
+		assert.Equal(t, int32(2), third.GetValue(), "Expected a new value after TTL")
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "Expected factory to be called twice")
+	})
+
+	t.Run("Pending Promise is shared", func(t *testing.T) {
+		cache := NewPromiseCache()
+		var resolveLater func(interface{}, error)
+		var calls int32
+		factory := func() *Promise {
+			atomic.AddInt32(&calls, 1)
+			return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				resolveLater = resolve
+			})
+		}
+
+		first := cache.Get("key", factory)
+		second := cache.Get("key", factory)
+		resolveLater("Hello, World!", nil)
+
+		assert.Same(t, first, second, "Expected the in-flight Promise to be shared")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Expected factory to be called once")
+		assert.Equal(t, "Hello, World!", second.GetValue(), "Expected value to be 'Hello, World!'")
+	})
+
+	t.Run("Rejection is not cached by default", func(t *testing.T) {
+		cache := NewPromiseCache()
+		var calls int32
+		factory := makeCacheFactory(&calls, func(n int32) bool { return n == 1 })
+
+		first := cache.Get("key", factory)
+		second := cache.Get("key", factory)
+
+		assert.Equal(t, "attempt 1 failed", first.GetReason().Error(), "Expected reason to be 'attempt 1 failed'")
+		assert.Equal(t, int32(2), second.GetValue(), "Expected the rejection not to be cached")
+		assert.Equal(t, 1, cache.Len(), "Expected one entry")
+	})
+
+	t.Run("Rejection is cached with error TTL", func(t *testing.T) {
+		clock := newTestClock()
+		cache := NewPromiseCache(WithCacheTTL(time.Minute), WithCacheErrorTTL(time.Second), WithCacheClock(clock.Now))
+		var calls int32
+		factory := makeCacheFactory(&calls, func(n int32) bool { return n == 1 })
+
+		first := cache.Get("key", factory)
+		assert.Same(t, first, cache.Get("key", factory), "Expected the rejection to be cached")
+
+		clock.Advance(time.Second)
+		second := cache.Get("key", factory)
+
+		assert.Equal(t, int32(2), second.GetValue(), "Expected a new value after error TTL")
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "Expected factory to be called twice")
+	})
+
+	t.Run("Least recently used entry is evicted", func(t *testing.T) {
+		cache := NewPromiseCache(WithCacheMaxEntries(2))
+		var calls int32
+		factory := makeCacheFactory(&calls, nil)
+
+		a := cache.Get("a", factory)
+		cache.Get("b", factory)
+		cache.Get("a", factory)
+		cache.Get("c", factory)
+
+		assert.Equal(t, 2, cache.Len(), "Expected two entries")
+		assert.Same(t, a, cache.Get("a", factory), "Expected 'a' to stay cached")
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls), "Expected no extra calls for 'a'")
+
+		cache.Get("b", factory)
+		assert.Equal(t, int32(4), atomic.LoadInt32(&calls), "Expected 'b' to have been evicted")
+	})
+
+	t.Run("Stale value is served while revalidating", func(t *testing.T) {
+		clock := newTestClock()
+		cache := NewPromiseCache(WithCacheTTL(time.Minute), WithStaleWhileRevalidate(time.Minute), WithCacheClock(clock.Now))
+		var calls int32
+		release := make(chan struct{})
+		factory := func() *Promise {
+			n := atomic.AddInt32(&calls, 1)
+			if n == 1 {
+				return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+					resolve(n, nil)
+				})
+			}
+			return NewPromiseAsync(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				<-release
+				resolve(n, nil)
+			})
+		}
+
+		first := cache.Get("key", factory)
+		clock.Advance(90 * time.Second)
+
+		stale := cache.Get("key", factory)
+		assert.Same(t, first, stale, "Expected the stale Promise while revalidating")
+		assert.Same(t, first, cache.Get("key", factory), "Expected only one refresh at a time")
+
+		close(release)
+		waitCacheRefreshed(t, cache, "key")
+
+		assert.Equal(t, int32(2), cache.Get("key", factory).GetValue(), "Expected the refreshed value")
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "Expected one refresh call")
+	})
+
+	t.Run("Slow synchronous refresh does not block", func(t *testing.T) {
+		clock := newTestClock()
+		cache := NewPromiseCache(WithCacheTTL(time.Minute), WithStaleWhileRevalidate(time.Minute), WithCacheClock(clock.Now))
+		var calls int32
+		factory := func() *Promise {
+			n := atomic.AddInt32(&calls, 1)
+			return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				if n > 1 {
+					time.Sleep(300 * time.Millisecond)
+				}
+				resolve(n, nil)
+			})
+		}
+
+		first := cache.Get("key", factory)
+		clock.Advance(90 * time.Second)
+
+		start := time.Now()
+		stale := cache.Get("key", factory)
+		elapsed := time.Since(start)
+
+		assert.Same(t, first, stale, "Expected the stale Promise")
+		assert.Less(t, elapsed, 100*time.Millisecond, "Expected Get not to wait for the refresh")
+
+		waitCacheRefreshed(t, cache, "key")
+		assert.Equal(t, int32(2), cache.Get("key", factory).GetValue(), "Expected the refreshed value")
+	})
+
+	t.Run("Failed refresh keeps the stale value until the window ends", func(t *testing.T) {
+		clock := newTestClock()
+		cache := NewPromiseCache(WithCacheTTL(time.Minute), WithStaleWhileRevalidate(time.Minute), WithCacheClock(clock.Now))
+		var calls int32
+		factory := makeCacheFactory(&calls, func(n int32) bool { return n == 2 })
+
+		first := cache.Get("key", factory)
+		clock.Advance(90 * time.Second)
+
+		assert.Same(t, first, cache.Get("key", factory), "Expected the stale Promise while refreshing")
+		waitCacheRefreshed(t, cache, "key")
+
+		cache.mu.Lock()
+		kept := cache.entries["key"].promise
+		cache.mu.Unlock()
+		assert.Same(t, first, kept, "Expected the stale Promise to be kept after a failed refresh")
+
+		clock.Advance(time.Minute)
+		assert.Equal(t, int32(3), cache.Get("key", factory).GetValue(), "Expected a new value after the stale window")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		cache := NewPromiseCache()
+		var calls int32
+		factory := makeCacheFactory(&calls, nil)
+
+		first := cache.Get("key", factory)
+		cache.Delete("key")
+
+		assert.Equal(t, 0, cache.Len(), "Expected no entries")
+		assert.NotSame(t, first, cache.Get("key", factory), "Expected a new Promise after Delete")
+	})
+
+	t.Run("Nil factory", func(t *testing.T) {
+		cache := NewPromiseCache()
+
+		assert.Nil(t, cache.Get("key", nil), "Expected nil for a nil factory")
+		assert.Equal(t, ErrNilPromise, cache.Get("key", func() *Promise { return nil }).GetReason(), "Expected reason to be ErrNilPromise")
+	})
+}
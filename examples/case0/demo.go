@@ -19,6 +19,9 @@ func main() {
 		return value.(string) + " !!", nil
 	}, nil)
 
+	// 等待 promise 结束
+	<-result.Done()
+
 	// 从 promise 中获取值并打印
 	fmt.Println(result.GetValue())
 }
@@ -51,6 +51,9 @@ func main() {
 
 	})
 
+	// 等待 promise 结束
+	<-result.Done()
+
 	// 输出 Promise 的拒绝原因，这里一定是 "nil"
 	fmt.Println("reason: ", result.GetReason())
 
@@ -37,6 +37,9 @@ func main() {
 
 	})
 
+	// 等待 promise 结束
+	<-result.Done()
+
 	// 输出 Promise 的拒绝原因
 	fmt.Println("reason: ", result.GetReason())
 
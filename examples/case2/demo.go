@@ -19,6 +19,9 @@ func main() {
 		return nil, fmt.Errorf("rejected.")
 	})
 
+	// 等待 promise 结束
+	<-result.Done()
+
 	// 从 promise 中获取值并打印
 	fmt.Println("Resolve:", result.GetValue())
 
@@ -34,6 +37,9 @@ func main() {
 		return nil, fmt.Errorf("rejected.")
 	})
 
+	// 等待 promise 结束
+	<-result.Done()
+
 	// 从 promise 中获取拒绝的原因并打印
 	fmt.Println("Rejected:", result.GetReason().Error())
 }
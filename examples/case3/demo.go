@@ -30,6 +30,9 @@ func main() {
 		return nil
 	})
 
+	// 等待 promise 结束
+	<-result.Done()
+
 	// 使用 Printf 函数输出 "finally 1 is called. value: %v, error: %v\n" 到控制台
 	// 使用 result.GetValue() 和 result.GetReason() 作为 Printf 函数的参数
 	fmt.Printf("finally 1 is called. value: %v, error: %v\n", result.GetValue(), result.GetReason())
@@ -64,6 +67,9 @@ func main() {
 		return nil, errors.New("Handled error: " + reason.Error())
 	})
 
+	// 等待 promise 结束
+	<-result.Done()
+
 	// 使用 Printf 函数输出 "finally 1 error, but then is called. value: %v, error: %v\n" 到控制台
 	// 使用 result.GetValue() 和 result.GetReason().Error() 作为 Printf 函数的参数
 	fmt.Printf("finally 1 error, but then is called. value: %v, error: %v\n", result.GetValue(), result.GetReason().Error())
@@ -89,6 +95,9 @@ func main() {
 		return nil
 	})
 
+	// 等待 promise 结束
+	<-result.Done()
+
 	// 使用 Printf 函数输出 "finally 2 is called. value: %v, error: %v\n" 到控制台
 	// 使用 result.GetValue() 和 result.GetReason() 作为 Printf 函数的参数
 	fmt.Printf("finally 2 is called. value: %v, error: %v\n", result.GetValue(), result.GetReason())
@@ -127,6 +136,9 @@ func main() {
 		return nil, errors.New("Handled error: " + reason.Error())
 	})
 
+	// 等待 promise 结束
+	<-result.Done()
+
 	// 使用 Printf 函数输出 "finally 2 error, but then is called. value: %v, error: %v\n" 到控制台
 	// 使用 result.GetValue() 和 result.GetReason().Error() 作为 Printf 函数的参数
 	fmt.Printf("finally 2 error, but then is called. value: %v, error: %v\n", result.GetValue(), result.GetReason().Error())
@@ -17,10 +17,13 @@ func main() {
 			resolve(value.(string)+" vowlink(NewPromise)", nil)
 		}), nil
 	}, nil).Then(func(value interface{}) (interface{}, error) {
-		// 在这个 Then 方法中，我们获取前一个 promise 的值，并加上 " !!"
-		return value.(*vl.Promise).GetValue().(string) + " !!", nil
+		// 返回的 promise 会被自动展开，这里直接得到它的值，并加上 " !!"
+		return value.(string) + " !!", nil
 	}, nil)
 
+	// 等待 promise 结束
+	<-result.Done()
+
 	// 从 promise 中获取值并打印
 	fmt.Println(result.GetValue())
 }
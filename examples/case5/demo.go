@@ -35,6 +35,9 @@ func main() {
 	// All() 将等待所有的 promise 被解析，并返回一个带有所有值的 promise
 	result := vl.All(p1, p2, p3)
 
+	// 等待 promise 结束
+	<-result.Done()
+
 	// 从 promise 中获取所有的值并打印
 	for i, str := range result.GetValue().([]interface{}) {
 		fmt.Println(">>", i, str.(string))
@@ -35,6 +35,9 @@ func main() {
 	// Race() 将等待第一个 promise 被解析，并返回一个带有值的 promise
 	result := vl.Race(p1, p2, p3)
 
+	// 等待 promise 结束
+	<-result.Done()
+
 	// 从 promise 中获取值并打印
 	fmt.Println(">>", result.GetValue().(string))
 }
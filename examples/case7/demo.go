@@ -36,6 +36,9 @@ func main() {
 	// Any() 将等待第一个 promise 被解析，并返回一个带有值的 promise
 	result := vl.Any(p1, p2, p3)
 
+	// 等待 promise 结束
+	<-result.Done()
+
 	// 从 promise 中获取值并打印
 	fmt.Println(">>", result.GetValue().(string))
 
@@ -58,6 +61,9 @@ func main() {
 	// Any() 将等待所有的 promise 被拒绝，并返回一个带有原因 `AggregateError` 的 promise
 	result = vl.Any(p1, p2, p3)
 
+	// 等待 promise 结束
+	<-result.Done()
+
 	// 从 promise 中获取原因并打印
 	fmt.Println("!!", result.GetReason().Error())
 }
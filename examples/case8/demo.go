@@ -27,14 +27,17 @@ func main() {
 	// AllSettled() 将等待所有的 promise 被解析或拒绝，并返回一个带有值的 promise
 	result := vl.AllSettled(p1, p2, p3)
 
+	// 等待 promise 结束
+	<-result.Done()
+
 	// 从 promise 中获取所有的结果
-	for i, r := range result.GetValue().([]interface{}) {
-		// 如果结果是一个错误，打印错误信息
-		if v, ok := r.(error); ok {
-			fmt.Println("!!", i, v.Error())
+	for _, r := range result.GetValue().([]vl.SettledResult) {
+		// 如果结果被拒绝，打印错误信息
+		if r.IsRejected() {
+			fmt.Println("!!", r.Index, r.Reason.Error())
 		} else {
 			// 否则，打印结果值
-			fmt.Println(">>", i, r.(string))
+			fmt.Println(">>", r.Index, r.Value.(string))
 		}
 	}
 }
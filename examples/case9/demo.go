@@ -50,6 +50,9 @@ func main() {
 		return nil, errors.New("Should be here.")
 	})
 
+	// 等待 promise 结束
+	<-result.Done()
+
 	// 打印 Promise 被拒绝的原因
 	fmt.Println("reason: ", result.GetReason())
 
@@ -0,0 +1,55 @@
+package vowlink
+
+import "sync"
+
+// Group 对相同 key 的并发调用进行去重，类似 golang.org/x/sync/singleflight
+// 同一个 key 在结束前只会调用一次工厂函数，所有调用者得到同一个 Promise，可以各自在其上注册 Then、Catch 和 Finally
+// Group 的零值可以直接使用
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*Promise
+}
+
+// Do 返回 key 对应的正在进行中的 Promise，不存在时调用 factory 创建
+// 返回的 Promise 跟随 factory 创建的 Promise 的状态，结束后 key 会被遗忘，之后的调用会再次调用 factory
+// factory 返回 nil 时 Promise 以 ErrNilPromise 被拒绝，发生 panic 时以 PanicError 被拒绝
+// 返回的 Promise 被所有调用者共享，取消它会影响所有调用者
+func (g *Group) Do(key string, factory func() *Promise) *Promise {
+	if factory == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*Promise)
+	}
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		return call
+	}
+	call := &Promise{state: Pending}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	// 遗忘 key 不会消费拒绝原因，没有调用者处理的拒绝仍会被报告为未处理
+	call.listen(func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		if g.calls[key] == call {
+			delete(g.calls, key)
+		}
+	})
+
+	call.resolve(callFactory(factory), nil)
+
+	return call
+}
+
+// Forget 遗忘 key 对应的正在进行中的 Promise，之后的 Do 会再次调用工厂函数，而不是等待之前的 Promise
+func (g *Group) Forget(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.calls, key)
+}
@@ -0,0 +1,118 @@
+package vowlink
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup_Do(t *testing.T) {
+	t.Run("Concurrent callers share one Promise", func(t *testing.T) {
+		var g Group
+		var calls int32
+		var resolveLater func(interface{}, error)
+		factory := func() *Promise {
+			atomic.AddInt32(&calls, 1)
+			return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				resolveLater = resolve
+			})
+		}
+
+		first := g.Do("key", factory)
+
+		var wg sync.WaitGroup
+		results := make([]*Promise, 10)
+		for i := range results {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = g.Do("key", factory)
+			}()
+		}
+		wg.Wait()
+
+		for _, result := range results {
+			assert.Same(t, first, result, "Expected all callers to get the same Promise")
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Expected factory to be called once")
+
+		values := first.Then(func(value interface{}) (interface{}, error) {
+			return value.(string) + " vowlink", nil
+		}, nil)
+		resolveLater("Hello, World!", nil)
+		waitSettled(t, values)
+
+		assert.Equal(t, "Hello, World!", first.GetValue(), "Expected value to be 'Hello, World!'")
+		assert.Equal(t, "Hello, World! vowlink", values.GetValue(), "Expected value to be 'Hello, World! vowlink'")
+	})
+
+	t.Run("Key is forgotten after settlement", func(t *testing.T) {
+		var g Group
+		var calls int32
+		factory := func() *Promise {
+			n := atomic.AddInt32(&calls, 1)
+			return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				if n == 1 {
+					reject(nil, errors.New("Something went wrong"))
+				} else {
+					resolve(n, nil)
+				}
+			})
+		}
+
+		first := g.Do("key", factory)
+		second := g.Do("key", factory)
+
+		assert.NotSame(t, first, second, "Expected a new Promise after settlement")
+		assert.Equal(t, "Something went wrong", first.GetReason().Error(), "Expected reason to be 'Something went wrong'")
+		assert.Equal(t, int32(2), second.GetValue(), "Expected value to be 2")
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "Expected factory to be called twice")
+	})
+
+	t.Run("Different keys do not share", func(t *testing.T) {
+		var g Group
+		factory := func() *Promise {
+			return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+		}
+
+		assert.NotSame(t, g.Do("a", factory), g.Do("b", factory), "Expected different Promises for different keys")
+	})
+
+	t.Run("Forget", func(t *testing.T) {
+		var g Group
+		factory := func() *Promise {
+			return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+		}
+
+		first := g.Do("key", factory)
+		g.Forget("key")
+		second := g.Do("key", factory)
+
+		assert.NotSame(t, first, second, "Expected a new Promise after Forget")
+
+		first.Cancel(nil)
+		assert.Same(t, second, g.Do("key", factory), "Expected settling a forgotten Promise to keep the new one")
+	})
+
+	t.Run("Nil factory and nil Promise", func(t *testing.T) {
+		var g Group
+
+		assert.Nil(t, g.Do("key", nil), "Expected nil for a nil factory")
+
+		result := g.Do("key", func() *Promise { return nil })
+		assert.Equal(t, ErrNilPromise, result.GetReason(), "Expected reason to be ErrNilPromise")
+	})
+
+	t.Run("Factory panic", func(t *testing.T) {
+		var g Group
+
+		result := g.Do("key", func() *Promise { panic("boom") })
+
+		assert.Equal(t, Rejected, result.State(), "Expected state to be Rejected")
+		assert.IsType(t, &PanicError{}, result.GetReason(), "Expected reason to be a PanicError")
+	})
+}
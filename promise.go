@@ -1,8 +1,15 @@
 package vowlink
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // PromiseState 表示 Promise 的状态
@@ -15,23 +22,96 @@ var (
 	defaultCleanupHandler = func() error { return nil }
 )
 
+// Promise 操作的预定义错误
+var (
+	ErrTimeout      = errors.New("promise timed out")            // Promise 未能在指定的时间内结束
+	ErrCancelled    = errors.New("promise cancelled")            // Promise 被取消且未指定原因
+	ErrNilPromise   = errors.New("promise factory returned nil") // Promise 工厂函数返回了 nil
+	ErrPromiseCycle = errors.New("promise resolved with itself") // Promise 以自身作为结果被解决
+
+	ErrNotEnoughPromises = errors.New("not enough promises to reach quorum") // Some 需要的成功数量大于输入数量
+
+	ErrRejectedWithoutReason = errors.New("promise rejected without reason") // 严格模式下 reject 未指定原因
+)
+
+// Thenable 表示可以被 Promise 吸收的类 Promise 对象，实现方不需要依赖 vowlink
+// 以 Thenable 作为值解决 Promise 时，Promise 会调用其 Then 方法并跟随其结果，而不是把对象本身作为值
+// Then 应当在对象结束时调用 onFulfilled 或 onRejected，只有第一次调用会生效
+// *Promise 以及提供 Untyped() *Promise 方法的包装类型（例如 typed.Promise）会被直接跟随，不需要实现该接口
+type Thenable interface {
+	Then(onFulfilled func(interface{}), onRejected func(error))
+}
+
+// 可以返回底层 Promise 的包装类型，例如 typed.Promise
+type promiseWrapper interface {
+	Untyped() *Promise
+}
+
 // AggregateError 表示错误集合
+// 由组合函数产生时，Errors 中的位置与输入的位置一一对应，没有被拒绝的输入对应 nil
 type AggregateError struct {
 	Errors []error
 }
 
+// IndexedError 表示 AggregateError 中的单个错误及其在输入中的位置
+type IndexedError struct {
+	Index int
+	Err   error
+}
+
 func (ae *AggregateError) Error() string {
-	if len(ae.Errors) == 0 {
+	errs := ae.Unwrap()
+	if len(errs) == 0 {
 		return "All promises were rejected"
 	}
 
-	errStrings := make([]string, 0, len(ae.Errors))
+	errStrings := make([]string, 0, len(errs))
+	for _, err := range errs {
+		errStrings = append(errStrings, err.Error())
+	}
+	return "All promises were rejected: " + strings.Join(errStrings, ", ")
+}
+
+// Unwrap 返回所有非 nil 的错误，使 Go 1.20 及以上版本的 errors.Is 和 errors.As 可以检查每个错误
+func (ae *AggregateError) Unwrap() []error {
+	errs := make([]error, 0, len(ae.Errors))
 	for _, err := range ae.Errors {
 		if err != nil {
-			errStrings = append(errStrings, err.Error())
+			errs = append(errs, err)
 		}
 	}
-	return "All promises were rejected: " + strings.Join(errStrings, ", ")
+	return errs
+}
+
+// Is 判断是否有任意一个错误与 target 匹配，使较早版本的 Go 也能通过 errors.Is 检查每个错误
+func (ae *AggregateError) Is(target error) bool {
+	for _, err := range ae.Errors {
+		if err != nil && errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As 查找第一个与 target 类型匹配的错误，使较早版本的 Go 也能通过 errors.As 检查每个错误
+func (ae *AggregateError) As(target interface{}) bool {
+	for _, err := range ae.Errors {
+		if err != nil && errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// IndexedErrors 返回所有非 nil 的错误及其在 Errors 中的位置，跳过规则与 Error 一致
+func (ae *AggregateError) IndexedErrors() []IndexedError {
+	errs := make([]IndexedError, 0, len(ae.Errors))
+	for i, err := range ae.Errors {
+		if err != nil {
+			errs = append(errs, IndexedError{Index: i, Err: err})
+		}
+	}
+	return errs
 }
 
 func NewAggregateError(capacity int) *AggregateError {
@@ -40,31 +120,269 @@ func NewAggregateError(capacity int) *AggregateError {
 	}
 }
 
+// JoinErrors 将多个错误合并为一个 AggregateError，并保留每个错误的位置
+// 与 errors.Join 一致，所有错误都为 nil 时返回 nil
+func JoinErrors(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			joined := make([]error, len(errs))
+			copy(joined, errs)
+			return &AggregateError{Errors: joined}
+		}
+	}
+	return nil
+}
+
+// PanicError 表示处理函数执行时发生的 panic
+type PanicError struct {
+	Value interface{} // recover() 得到的值
+	Stack []byte      // 发生 panic 时的调用栈
+}
+
+func (pe *PanicError) Error() string {
+	return fmt.Sprintf("promise handler panicked: %v", pe.Value)
+}
+
+// Unwrap 在 panic 的值是 error 时返回该 error
+func (pe *PanicError) Unwrap() error {
+	err, _ := pe.Value.(error)
+	return err
+}
+
+// 是否将处理函数中的 panic 转换为 Promise 的拒绝，默认开启
+var panicRecovery int32 = 1
+
+// SetPanicRecovery 设置是否恢复处理函数中的 panic
+// 开启时（默认），panic 会被转换为携带 PanicError 的拒绝；关闭时，panic 会直接向上传播
+// 注意 Then、Catch 和 Finally 的处理函数在内部的 goroutine 中执行，关闭时其中的 panic 会使程序崩溃
+func SetPanicRecovery(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&panicRecovery, value)
+}
+
+func isPanicRecoveryEnabled() bool {
+	return atomic.LoadInt32(&panicRecovery) == 1
+}
+
+// ChainError 表示在 Promise 链的某个阶段产生的拒绝
+type ChainError struct {
+	Stage int    // 产生拒绝的阶段，NewPromise 等创建的 Promise 为 0，每经过一次 Then、Catch 或 Finally 加 1
+	Label string // 阶段的名称，通过 ThenNamed 或 Label 设置
+	Cause error  // 原始的拒绝原因
+}
+
+func (ce *ChainError) Error() string {
+	if ce.Label != "" {
+		return fmt.Sprintf("stage %d (%s): %v", ce.Stage, ce.Label, ce.Cause)
+	}
+	return fmt.Sprintf("stage %d: %v", ce.Stage, ce.Cause)
+}
+
+// Unwrap 返回原始的拒绝原因
+func (ce *ChainError) Unwrap() error {
+	return ce.Cause
+}
+
+// 是否使用 ChainError 包装拒绝原因，默认关闭
+var chainErrors int32
+
+// SetChainErrors 设置是否使用 ChainError 包装拒绝原因
+// 开启时，Promise 被拒绝时如果原因还不是 ChainError，会被包装为记录了当前阶段和名称的 ChainError，
+// 已经是 ChainError 的原因沿链传递时保持不变，因此最终的原因指向最初产生拒绝的阶段
+func SetChainErrors(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&chainErrors, value)
+}
+
+func isChainErrorsEnabled() bool {
+	return atomic.LoadInt32(&chainErrors) == 1
+}
+
+// 是否使用严格模式处理未指定原因的拒绝，默认关闭
+var strictMode int32
+
+// SetStrictMode 设置 reject(value, nil) 的处理方式，保证 Promise 的状态与链条实际走的路径一致
+// 关闭时（默认），reject(value, nil) 视为以 value 完成，Promise 进入 Fulfilled 状态，链条走成功路径；
+// 开启时，Promise 以 ErrRejectedWithoutReason 被拒绝，链条走错误路径
+// 无论是否开启，resolve(value, reason) 在 reason 不为 nil 时都会拒绝 Promise
+func SetStrictMode(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&strictMode, value)
+}
+
+func isStrictModeEnabled() bool {
+	return atomic.LoadInt32(&strictMode) == 1
+}
+
+// SettledResult 表示 AllSettled 中单个 Promise 的结束结果
+type SettledResult struct {
+	State  PromiseState // Fulfilled、Rejected 或 Cancelled
+	Value  interface{}  // 完成时的值
+	Reason error        // 拒绝或取消时的原因
+	Index  int          // 在输入中的位置
+}
+
+// 根据 Promise 的结束状态创建 SettledResult
+func newSettledResult(index int, state PromiseState, value interface{}, reason error) SettledResult {
+	switch {
+	case state == Cancelled:
+		return SettledResult{State: Cancelled, Reason: reason, Index: index}
+	case reason != nil:
+		return SettledResult{State: Rejected, Reason: reason, Index: index}
+	default:
+		return SettledResult{State: Fulfilled, Value: value, Index: index}
+	}
+}
+
+// IsFulfilled 判断结果是否为已完成
+func (r SettledResult) IsFulfilled() bool {
+	return r.State == Fulfilled
+}
+
+// IsRejected 判断结果是否为已拒绝
+func (r SettledResult) IsRejected() bool {
+	return r.State == Rejected
+}
+
+// IsCancelled 判断结果是否为已取消
+func (r SettledResult) IsCancelled() bool {
+	return r.State == Cancelled
+}
+
+// PartitionSettledResults 将 AllSettled 的结果拆分为已完成和未完成两组，各组保持原有顺序
+// 已取消的结果与已拒绝的结果归为一组
+func PartitionSettledResults(results []SettledResult) (fulfilled, rejected []SettledResult) {
+	for _, result := range results {
+		if result.IsFulfilled() {
+			fulfilled = append(fulfilled, result)
+		} else {
+			rejected = append(rejected, result)
+		}
+	}
+	return fulfilled, rejected
+}
+
 // Promise 状态常量
 const (
 	Pending   PromiseState = iota // 等待中
 	Fulfilled                     // 已完成
 	Rejected                      // 已拒绝
+	Cancelled                     // 已取消
 )
 
+func (s PromiseState) String() string {
+	switch s {
+	case Pending:
+		return "pending"
+	case Fulfilled:
+		return "fulfilled"
+	case Rejected:
+		return "rejected"
+	case Cancelled:
+		return "cancelled"
+	default:
+		return fmt.Sprintf("PromiseState(%d)", uint8(s))
+	}
+}
+
 // Promise 表示一个异步操作
 type Promise struct {
-	mu     sync.RWMutex
-	state  PromiseState
-	value  interface{}
-	reason error
+	mu        sync.RWMutex
+	state     PromiseState
+	value     interface{}
+	reason    error
+	callbacks []func()
+	done      chan struct{}
+	ctx       context.Context
+	upstream  []*Promise
+	stage     int
+	label     string
+	following bool     // 是否正在跟随另一个 Thenable 的状态，此时 resolve 和 reject 不再生效
+	watching  bool     // 是否已经在监听上下文的取消
+	queue     []func() // 等待执行的 Then、Catch 和 Finally 回调，按注册顺序执行
+	draining  bool     // 是否有 goroutine 正在执行 queue 中的回调
+
+	handled          int32 // 拒绝原因是否已被读取或传递，原子访问
+	tracked          int32 // 是否已注册未处理拒绝的终结器，原子访问
+	unhandledHandler UnhandledRejectionHandler
 }
 
 // 改变 Promise 的状态（仅在 Pending 状态下有效）
-func (p *Promise) change(state PromiseState, value interface{}, reason error) {
+// Promise 正在跟随另一个 Thenable 时，只有 force 为 true 的调用才会生效
+// 状态改变后，在锁外依次执行等待中的回调函数
+func (p *Promise) change(state PromiseState, value interface{}, reason error, force bool) {
+	p.mu.Lock()
+	if p.state != Pending || (p.following && !force) {
+		p.mu.Unlock()
+		return
+	}
+
+	if reason != nil && state != Cancelled && isChainErrorsEnabled() {
+		if _, ok := reason.(*ChainError); !ok {
+			reason = &ChainError{Stage: p.stage, Label: p.label, Cause: reason}
+		}
+	}
+
+	p.state = state
+	p.value = value
+	p.reason = reason
+	if p.done != nil {
+		close(p.done)
+	}
+	callbacks := p.callbacks
+	p.callbacks = nil
+	p.mu.Unlock()
+
+	if state == Rejected && reason != nil {
+		p.trackUnhandledRejection()
+	}
+
+	for _, callback := range callbacks {
+		callback()
+	}
+}
+
+// Done 返回一个在 Promise 结束时被关闭的通道，可以在 select 语句中使用
+func (p *Promise) Done() <-chan struct{} {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	if p.done == nil {
+		p.done = make(chan struct{})
+		if p.state != Pending {
+			close(p.done)
+		}
+	}
+	return p.done
+}
+
+// 注册 Promise 结束时要执行的回调函数，并将 Promise 标记为已处理
+// 如果 Promise 已经结束，回调函数会被立即执行
+func (p *Promise) subscribe(callback func()) {
+	p.markHandled()
+	p.listen(callback)
+}
+
+// 与 subscribe 相同，但不将 Promise 标记为已处理，用于不消费拒绝原因的内部记录
+func (p *Promise) listen(callback func()) {
+	p.mu.Lock()
 	if p.state == Pending {
-		p.state = state
-		p.value = value
-		p.reason = reason
+		p.callbacks = append(p.callbacks, callback)
+		p.mu.Unlock()
+		return
 	}
+	p.mu.Unlock()
+
+	callback()
 }
 
 func (p *Promise) snapshot() (PromiseState, interface{}, error) {
@@ -74,19 +392,128 @@ func (p *Promise) snapshot() (PromiseState, interface{}, error) {
 	return p.state, p.value, p.reason
 }
 
+// State 返回 Promise 当前的状态
+// Promise 为 Fulfilled 时拒绝原因一定为 nil，为 Rejected 或 Cancelled 时拒绝原因一定不为 nil
+func (p *Promise) State() PromiseState {
+	return p.getState()
+}
+
 func (p *Promise) getState() PromiseState {
 	state, _, _ := p.snapshot()
 	return state
 }
 
 // 将 Promise 标记为已完成
+// reason 不为 nil 时，Promise 会被拒绝；value 为 Thenable 时，Promise 会跟随其状态
 func (p *Promise) resolve(value interface{}, reason error) {
-	p.change(Fulfilled, value, reason)
+	if reason != nil {
+		p.change(Rejected, value, reason, false)
+		return
+	}
+	if source, thenable, ok := followTarget(value); ok {
+		p.mu.Lock()
+		if p.state != Pending || p.following {
+			p.mu.Unlock()
+			return
+		}
+		p.following = true
+		// 跟随的 Promise 作为上游，使 CancelUpstream 可以取消它
+		if source != nil && source != p {
+			p.upstream = append(p.upstream, source)
+		}
+		p.mu.Unlock()
+
+		// 跟随期间 Promise 不会再执行回调，需要单独监听上下文的取消
+		if p.ctx != nil {
+			p.watch()
+		}
+		p.adopt(source, thenable)
+		return
+	}
+	p.change(Fulfilled, value, nil, false)
 }
 
 // 将 Promise 标记为已拒绝
+// reason 为 nil 时，按照 SetStrictMode 的设置以 ErrRejectedWithoutReason 拒绝，或以 value 完成
 func (p *Promise) reject(value interface{}, reason error) {
-	p.change(Rejected, value, reason)
+	if reason == nil {
+		if !isStrictModeEnabled() {
+			p.resolve(value, nil)
+			return
+		}
+		reason = ErrRejectedWithoutReason
+	}
+	p.change(Rejected, value, reason, false)
+}
+
+// 判断 value 是否需要被跟随，返回要跟随的 Promise 或 Thenable
+// 值为 nil 的指针不会被跟随
+func followTarget(value interface{}) (*Promise, Thenable, bool) {
+	if value == nil {
+		return nil, nil, false
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil, false
+	}
+
+	switch v := value.(type) {
+	case *Promise:
+		return v, nil, true
+	case promiseWrapper:
+		if source := v.Untyped(); source != nil {
+			return source, nil, true
+		}
+	case Thenable:
+		return nil, v, true
+	}
+	return nil, nil, false
+}
+
+// 跟随 source 或 thenable 的状态，调用前 Promise 需要已被标记为 following
+// *Promise 直接订阅其结束，包括取消状态；Thenable 通过其 Then 方法跟随，且只采纳第一次结果
+func (p *Promise) adopt(source *Promise, thenable Thenable) {
+	if source != nil {
+		if source == p {
+			p.change(Rejected, nil, ErrPromiseCycle, true)
+			return
+		}
+		source.subscribe(func() {
+			state, value, reason := source.snapshot()
+			p.change(state, value, reason, true)
+		})
+		return
+	}
+
+	var once int32
+	settle := func(state PromiseState, value interface{}, reason error) {
+		if !atomic.CompareAndSwapInt32(&once, 0, 1) {
+			return
+		}
+		if state == Fulfilled {
+			if nextSource, nextThenable, ok := followTarget(value); ok {
+				p.adopt(nextSource, nextThenable)
+				return
+			}
+		}
+		p.change(state, value, reason, true)
+	}
+
+	if isPanicRecoveryEnabled() {
+		defer func() {
+			if r := recover(); r != nil {
+				settle(Rejected, nil, &PanicError{Value: r, Stack: debug.Stack()})
+			}
+		}()
+	}
+
+	thenable.Then(func(value interface{}) {
+		settle(Fulfilled, value, nil)
+	}, func(reason error) {
+		if reason == nil {
+			reason = ErrRejectedWithoutReason
+		}
+		settle(Rejected, nil, reason)
+	})
 }
 
 // NewPromise 使用给定的处理函数创建新的 Promise
@@ -97,13 +524,83 @@ func NewPromise(promiseHandler func(resolve func(interface{}, error), reject fun
 
 	p := &Promise{state: Pending}
 
-	promiseHandler(p.resolve, p.reject)
+	p.run(func() { promiseHandler(p.resolve, p.reject) })
+
+	return p
+}
+
+// NewPromiseAsync 使用给定的处理函数创建新的 Promise，处理函数在新的 goroutine 中执行
+// 返回的 Promise 在处理函数调用 resolve 或 reject 之前处于 Pending 状态
+func NewPromiseAsync(promiseHandler func(resolve func(interface{}, error), reject func(interface{}, error))) *Promise {
+	if promiseHandler == nil {
+		return nil
+	}
+
+	p := &Promise{state: Pending}
+
+	go p.run(func() { promiseHandler(p.resolve, p.reject) })
 
 	return p
 }
 
+// NewPromiseWithContext 使用给定的上下文和处理函数创建新的 Promise
+// 如果 ctx 在 Promise 结束前被取消，Promise 会以 ctx.Err() 被拒绝
+// 通过 Then、Catch 和 Finally 派生的 Promise 会继承该上下文
+func NewPromiseWithContext(ctx context.Context, promiseHandler func(ctx context.Context, resolve func(interface{}, error), reject func(interface{}, error))) *Promise {
+	if promiseHandler == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	p := &Promise{state: Pending, ctx: ctx}
+
+	if err := ctx.Err(); err != nil {
+		p.reject(nil, err)
+		return p
+	}
+
+	p.watch()
+
+	p.run(func() { promiseHandler(ctx, p.resolve, p.reject) })
+
+	return p
+}
+
+// 在上下文被取消时以 ctx.Err() 拒绝 Promise，Promise 结束后停止监听
+// 重复调用时只会监听一次
+func (p *Promise) watch() {
+	ctxDone := p.ctx.Done()
+	if ctxDone == nil {
+		return
+	}
+
+	p.mu.Lock()
+	if p.watching {
+		p.mu.Unlock()
+		return
+	}
+	p.watching = true
+	p.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctxDone:
+			p.change(Rejected, nil, p.ctx.Err(), true)
+		case <-p.Done():
+		}
+	}()
+}
+
 // Then 注册 Promise 完成时要调用的回调函数
+// 回调函数总是在 Then 返回后，于 Promise 结束时在内部的 goroutine 中执行，读取返回的 Promise 的结果前需要通过 Await 或 Done 等待
 func (p *Promise) Then(successHandler func(interface{}) (interface{}, error), errorHandler func(error) (interface{}, error)) *Promise {
+	return p.ThenNamed("", successHandler, errorHandler)
+}
+
+// ThenNamed 与 Then 相同，并为派生的 Promise 设置阶段名称，用于 ChainError
+func (p *Promise) ThenNamed(label string, successHandler func(interface{}) (interface{}, error), errorHandler func(error) (interface{}, error)) *Promise {
 	if successHandler == nil {
 		successHandler = defaultSuccessHandler
 	}
@@ -111,17 +608,111 @@ func (p *Promise) Then(successHandler func(interface{}) (interface{}, error), er
 		errorHandler = defaultErrorHandler
 	}
 
-	return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+	return p.derive(label, func(child *Promise, value interface{}, reason error) {
+		var err error
+		if reason != nil {
+			value, err = errorHandler(reason)
+		} else {
+			value, err = successHandler(value)
+		}
+
+		// 回调的返回结果决定派生 Promise 的状态：返回错误时被拒绝，否则被解决（包括错误回调恢复的情况）
+		if err != nil {
+			child.reject(value, err)
+		} else {
+			child.resolve(value, nil)
+		}
+	}, nil)
+}
+
+// Label 设置 Promise 的阶段名称，用于 ChainError，需要在 Promise 结束前调用才会生效
+func (p *Promise) Label(label string) *Promise {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.label = label
+	return p
+}
+
+// 创建一个继承上下文的派生 Promise，并在当前 Promise 结束后异步调用 handler
+// handler 不会在注册它的调用返回前执行，同一个 Promise 上的 handler 按注册顺序执行（Promises/A+ 2.2.4 和 2.2.6）
+// 上下文已被取消时，handler 收到的 reason 为 ctx.Err()
+// 当前 Promise 被取消时，派生 Promise 会被立即取消，此时不调用 handler，只调用 cancelHandler
+func (p *Promise) derive(label string, handler func(child *Promise, value interface{}, reason error), cancelHandler func()) *Promise {
+	child := &Promise{state: Pending, ctx: p.ctx, upstream: []*Promise{p}, stage: p.stage + 1, label: label}
+
+	p.subscribe(func() {
 		state, value, reason := p.snapshot()
-		switch state {
-		case Fulfilled, Rejected:
-			if reason != nil {
-				reject(errorHandler(reason))
-			} else {
-				resolve(successHandler(value))
+		if state == Cancelled {
+			if cancelHandler != nil {
+				child.run(cancelHandler)
 			}
+			child.cancel(reason)
+			return
 		}
+
+		p.schedule(func() {
+			// 派生 Promise 在等待期间已被取消时，不再调用 handler
+			if child.getState() != Pending {
+				return
+			}
+			// 上下文已被取消时，跳过成功回调，以 ctx.Err() 走错误路径
+			if reason == nil && child.ctx != nil {
+				reason = child.ctx.Err()
+			}
+			child.run(func() { handler(child, value, reason) })
+		})
 	})
+
+	return child
+}
+
+// 将回调加入队列，由独立的 goroutine 按加入顺序依次执行
+func (p *Promise) schedule(callback func()) {
+	p.mu.Lock()
+	p.queue = append(p.queue, callback)
+	if p.draining {
+		p.mu.Unlock()
+		return
+	}
+	p.draining = true
+	p.mu.Unlock()
+
+	go p.drain()
+}
+
+// 依次执行队列中的回调，直到队列为空
+func (p *Promise) drain() {
+	for {
+		p.mu.Lock()
+		if len(p.queue) == 0 {
+			p.queue = nil
+			p.draining = false
+			p.mu.Unlock()
+			return
+		}
+		callback := p.queue[0]
+		p.queue = p.queue[1:]
+		p.mu.Unlock()
+
+		callback()
+	}
+}
+
+// 执行用户提供的函数，函数发生 panic 时以 PanicError 拒绝当前 Promise
+func (p *Promise) run(fn func()) {
+	if !isPanicRecoveryEnabled() {
+		fn()
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			p.reject(nil, &PanicError{Value: r, Stack: debug.Stack()})
+		}
+	}()
+
+	fn()
 }
 
 // Catch 注册 Promise 被拒绝时要调用的回调函数
@@ -135,22 +726,66 @@ func (p *Promise) Finally(cleanupHandler func() error) *Promise {
 		cleanupHandler = defaultCleanupHandler
 	}
 
-	return p.Then(
-		func(value interface{}) (interface{}, error) {
-			err := cleanupHandler()
-			if err != nil {
-				return nil, err
-			}
-			return value, nil
-		},
-		func(reason error) (interface{}, error) {
-			err := cleanupHandler()
-			if err != nil {
-				return nil, err
-			}
-			return nil, reason
-		},
-	)
+	return p.derive("", func(child *Promise, value interface{}, reason error) {
+		if err := cleanupHandler(); err != nil {
+			child.reject(nil, err)
+		} else if reason != nil {
+			child.reject(nil, reason)
+		} else {
+			child.resolve(value, nil)
+		}
+	}, func() {
+		_ = cleanupHandler()
+	})
+}
+
+// Cancel 取消处于 Pending 状态的 Promise，并将取消传播到通过 Then、Catch 和 Finally 派生的 Promise
+// 派生链上的成功和错误回调不会被调用，但 Finally 的清理回调仍会执行
+// reason 为 nil 时使用 ErrCancelled
+func (p *Promise) Cancel(reason error) {
+	if reason == nil {
+		reason = ErrCancelled
+	}
+	p.cancel(reason)
+}
+
+// CancelUpstream 与 Cancel 类似，但还会沿着派生关系向上取消上游的 Promise，
+// 包括组合函数（All、Any 等）的输入
+func (p *Promise) CancelUpstream(reason error) {
+	if reason == nil {
+		reason = ErrCancelled
+	}
+	p.cancelUpstream(reason, make(map[*Promise]struct{}))
+}
+
+// 取消 Promise 及其上游，visited 记录已经访问过的 Promise，避免相互跟随的 Promise 无限递归
+func (p *Promise) cancelUpstream(reason error, visited map[*Promise]struct{}) {
+	if _, ok := visited[p]; ok {
+		return
+	}
+	visited[p] = struct{}{}
+
+	p.cancel(reason)
+
+	p.mu.RLock()
+	upstreams := p.upstream
+	p.mu.RUnlock()
+
+	for _, upstream := range upstreams {
+		upstream.cancelUpstream(reason, visited)
+	}
+}
+
+// 记录 Promise 启动的上游 Promise，使 CancelUpstream 可以取消它
+func (p *Promise) addUpstream(upstream *Promise) {
+	p.mu.Lock()
+	p.upstream = append(p.upstream, upstream)
+	p.mu.Unlock()
+}
+
+// 将 Promise 标记为已取消
+func (p *Promise) cancel(reason error) {
+	p.change(Cancelled, nil, reason, true)
 }
 
 func (p *Promise) GetValue() interface{} {
@@ -159,132 +794,517 @@ func (p *Promise) GetValue() interface{} {
 }
 
 func (p *Promise) GetReason() error {
+	p.markHandled()
 	_, _, reason := p.snapshot()
 	return reason
 }
 
+// Await 阻塞等待 Promise 结束或 ctx 被取消
+// Promise 结束时返回其值和拒绝原因，ctx 被取消时返回 ctx.Err()
+func (p *Promise) Await(ctx context.Context) (interface{}, error) {
+	select {
+	case <-p.Done():
+		p.markHandled()
+		_, value, reason := p.snapshot()
+		return value, reason
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// WithTimeout 返回一个与当前 Promise 结果相同的 Promise，超时未结束时以 ErrTimeout 被拒绝
+func (p *Promise) WithTimeout(d time.Duration) *Promise {
+	return Timeout(p, d)
+}
+
+// 创建记录了上游输入的 Promise，供组合函数使用
+func newCombinedPromise(promises []*Promise, promiseHandler func(resolve func(interface{}, error), reject func(interface{}, error))) *Promise {
+	p := &Promise{state: Pending, upstream: promises[:len(promises):len(promises)]}
+
+	promiseHandler(p.resolve, p.reject)
+
+	return p
+}
+
+// 调用 Promise 工厂函数并返回其创建的 Promise
+// 工厂函数返回 nil 时返回以 ErrNilPromise 拒绝的 Promise，发生 panic 时返回以 PanicError 拒绝的 Promise
+func callFactory(factory func() *Promise) *Promise {
+	var next *Promise
+	failed := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+		next = factory()
+		if next == nil {
+			reject(nil, ErrNilPromise)
+		}
+	})
+
+	if next != nil {
+		return next
+	}
+	return failed
+}
+
+// 为每个 Promise 注册结束回调，回调参数为 Promise 在输入中的位置、状态、值和拒绝原因
+// 被取消的 Promise 同样带有非 nil 的原因，组合函数将其视为被拒绝
+// 输入可能在不同的 goroutine 中结束，回调函数需要自行保证并发安全
+func onEachSettled(promises []*Promise, callback func(index int, state PromiseState, value interface{}, reason error)) {
+	for i, promise := range promises {
+		i, promise := i, promise
+		promise.subscribe(func() {
+			state, value, reason := promise.snapshot()
+			callback(i, state, value, reason)
+		})
+	}
+}
+
 // All 等待所有 Promise 完成
 // 如果任何一个 Promise 被拒绝，结果 Promise 也会被拒绝
 func All(promises ...*Promise) *Promise {
-	return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+	return newCombinedPromise(promises, func(resolve func(interface{}, error), reject func(interface{}, error)) {
 		if len(promises) == 0 {
 			resolve([]interface{}{}, nil)
 			return
 		}
 
+		var mu sync.Mutex
 		values := make([]interface{}, len(promises))
 		pendingCount := len(promises)
 		isCompleted := false
 
-		for i, promise := range promises {
-			promise.Then(func(value interface{}) (interface{}, error) {
-				if !isCompleted {
-					values[i] = value
-					pendingCount--
-					if pendingCount == 0 {
-						resolve(values, nil)
-					}
-				}
-				return nil, nil
-			}, func(reason error) (interface{}, error) {
-				if !isCompleted {
-					isCompleted = true
-					reject(nil, reason)
-				}
-				return nil, nil
-			})
-		}
+		onEachSettled(promises, func(index int, _ PromiseState, value interface{}, reason error) {
+			mu.Lock()
+			if isCompleted {
+				mu.Unlock()
+				return
+			}
+			if reason != nil {
+				isCompleted = true
+				mu.Unlock()
+				reject(nil, reason)
+				return
+			}
+			values[index] = value
+			pendingCount--
+			isCompleted = pendingCount == 0
+			shouldResolve := isCompleted
+			mu.Unlock()
+
+			if shouldResolve {
+				resolve(values, nil)
+			}
+		})
 	})
 }
 
 // AllSettled 等待所有 Promise 完成，无论其状态如何
+// 结果 Promise 的值为按输入顺序排列的 []SettledResult
 func AllSettled(promises ...*Promise) *Promise {
-	return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+	return newCombinedPromise(promises, func(resolve func(interface{}, error), reject func(interface{}, error)) {
 		if len(promises) == 0 {
-			resolve([]interface{}{}, nil)
+			resolve([]SettledResult{}, nil)
 			return
 		}
 
-		values := make([]interface{}, len(promises))
+		var mu sync.Mutex
+		results := make([]SettledResult, len(promises))
 		pendingCount := len(promises)
 
-		for i, promise := range promises {
-			promise.Then(func(value interface{}) (interface{}, error) {
-				values[i] = value
-				pendingCount--
-				if pendingCount == 0 {
-					resolve(values, nil)
-				}
-				return nil, nil
-			}, func(reason error) (interface{}, error) {
-				values[i] = reason
-				pendingCount--
-				if pendingCount == 0 {
-					resolve(values, nil)
-				}
-				return nil, nil
-			})
-		}
+		onEachSettled(promises, func(index int, state PromiseState, value interface{}, reason error) {
+			mu.Lock()
+			results[index] = newSettledResult(index, state, value, reason)
+			pendingCount--
+			isCompleted := pendingCount == 0
+			mu.Unlock()
+
+			if isCompleted {
+				resolve(results, nil)
+			}
+		})
 	})
 }
 
+// 将以键索引的 Promise 拆分为键和 Promise 两个切片，两者位置一一对应
+func splitPromiseMap(promises map[string]*Promise) ([]string, []*Promise) {
+	keys := make([]string, 0, len(promises))
+	inputs := make([]*Promise, 0, len(promises))
+	for key, promise := range promises {
+		keys = append(keys, key)
+		inputs = append(inputs, promise)
+	}
+	return keys, inputs
+}
+
+// AllMap 等待所有以键索引的 Promise 完成，结果 Promise 的值为 map[string]interface{}
+// 如果任何一个 Promise 被拒绝，结果 Promise 也会被拒绝
+func AllMap(promises map[string]*Promise) *Promise {
+	keys, inputs := splitPromiseMap(promises)
+
+	return All(inputs...).Then(func(value interface{}) (interface{}, error) {
+		values := value.([]interface{})
+		result := make(map[string]interface{}, len(keys))
+		for i, key := range keys {
+			result[key] = values[i]
+		}
+		return result, nil
+	}, nil)
+}
+
+// AllSettledMap 等待所有以键索引的 Promise 完成，无论其状态如何
+// 结果 Promise 的值为 map[string]SettledResult，由于 map 没有位置，其中的 Index 均为 -1
+func AllSettledMap(promises map[string]*Promise) *Promise {
+	keys, inputs := splitPromiseMap(promises)
+
+	return AllSettled(inputs...).Then(func(value interface{}) (interface{}, error) {
+		results := value.([]SettledResult)
+		settled := make(map[string]SettledResult, len(keys))
+		for i, key := range keys {
+			result := results[i]
+			result.Index = -1
+			settled[key] = result
+		}
+		return settled, nil
+	}, nil)
+}
+
 // Any 返回一个在任意输入 Promise 成功时完成的 Promise
-// 如果所有 Promise 都被拒绝，返回一个 AggregateError
+// 如果所有 Promise 都被拒绝，返回一个按输入位置记录错误的 AggregateError
 func Any(promises ...*Promise) *Promise {
-	return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+	return newCombinedPromise(promises, func(resolve func(interface{}, error), reject func(interface{}, error)) {
 		if len(promises) == 0 {
 			reject(nil, NewAggregateError(0))
 			return
 		}
 
-		errors := NewAggregateError(len(promises))
+		var mu sync.Mutex
+		errors := &AggregateError{Errors: make([]error, len(promises))}
 		pendingCount := len(promises)
 		isCompleted := false
 
-		for _, promise := range promises {
-			promise.Then(func(value interface{}) (interface{}, error) {
-				if !isCompleted {
-					isCompleted = true
-					resolve(value, nil)
-				}
-				return nil, nil
-			}, func(reason error) (interface{}, error) {
-				if !isCompleted {
-					errors.Errors = append(errors.Errors, reason)
-					pendingCount--
-					if pendingCount == 0 {
-						reject(nil, errors)
-					}
-				}
-				return nil, nil
-			})
+		onEachSettled(promises, func(index int, _ PromiseState, value interface{}, reason error) {
+			mu.Lock()
+			if isCompleted {
+				mu.Unlock()
+				return
+			}
+			if reason == nil {
+				isCompleted = true
+				mu.Unlock()
+				resolve(value, nil)
+				return
+			}
+			errors.Errors[index] = reason
+			pendingCount--
+			isCompleted = pendingCount == 0
+			shouldReject := isCompleted
+			mu.Unlock()
+
+			if shouldReject {
+				reject(nil, errors)
+			}
+		})
+	})
+}
+
+// Some 返回一个在 n 个输入 Promise 成功时完成的 Promise，其值为按完成顺序排列的 n 个值
+// 一旦被拒绝的 Promise 过多，无法再达到 n 个成功，结果 Promise 以按输入位置记录错误的 AggregateError 被拒绝
+// n 小于等于 0 时立即以空切片完成；n 大于输入数量时立即以包装了 ErrNotEnoughPromises 的错误被拒绝
+// Any 相当于 n 为 1 的特例
+func Some(n int, promises ...*Promise) *Promise {
+	return newCombinedPromise(promises, func(resolve func(interface{}, error), reject func(interface{}, error)) {
+		// 结果不依赖输入时仍然订阅输入，输入之后的拒绝与其他组合函数一样视为已被处理
+		if n <= 0 || n > len(promises) {
+			onEachSettled(promises, func(int, PromiseState, interface{}, error) {})
 		}
+
+		if n <= 0 {
+			resolve([]interface{}{}, nil)
+			return
+		}
+		if n > len(promises) {
+			reject(nil, fmt.Errorf("%w: need %d, got %d", ErrNotEnoughPromises, n, len(promises)))
+			return
+		}
+
+		var mu sync.Mutex
+		values := make([]interface{}, 0, n)
+		errors := &AggregateError{Errors: make([]error, len(promises))}
+		maxFailures := len(promises) - n
+		failedCount := 0
+		isCompleted := false
+
+		onEachSettled(promises, func(index int, _ PromiseState, value interface{}, reason error) {
+			mu.Lock()
+			if isCompleted {
+				mu.Unlock()
+				return
+			}
+			if reason == nil {
+				values = append(values, value)
+				isCompleted = len(values) == n
+				shouldResolve := isCompleted
+				mu.Unlock()
+
+				if shouldResolve {
+					resolve(values, nil)
+				}
+				return
+			}
+			errors.Errors[index] = reason
+			failedCount++
+			isCompleted = failedCount > maxFailures
+			shouldReject := isCompleted
+			mu.Unlock()
+
+			if shouldReject {
+				reject(nil, errors)
+			}
+		})
 	})
 }
 
 // Race 返回一个与第一个完成的 Promise 具有相同状态的 Promise
 func Race(promises ...*Promise) *Promise {
-	return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+	return newCombinedPromise(promises, func(resolve func(interface{}, error), reject func(interface{}, error)) {
 		if len(promises) == 0 {
 			resolve(nil, nil)
 			return
 		}
 
+		var mu sync.Mutex
 		isCompleted := false
-		for _, promise := range promises {
-			promise.Then(func(value interface{}) (interface{}, error) {
-				if !isCompleted {
-					isCompleted = true
-					resolve(value, nil)
-				}
-				return nil, nil
-			}, func(reason error) (interface{}, error) {
-				if !isCompleted {
-					isCompleted = true
-					reject(nil, reason)
-				}
-				return nil, nil
-			})
+
+		onEachSettled(promises, func(index int, _ PromiseState, value interface{}, reason error) {
+			mu.Lock()
+			if isCompleted {
+				mu.Unlock()
+				return
+			}
+			isCompleted = true
+			mu.Unlock()
+
+			if reason != nil {
+				reject(nil, reason)
+			} else {
+				resolve(value, nil)
+			}
+		})
+	})
+}
+
+// Timeout 返回一个与 p 结果相同的 Promise
+// 如果 p 未能在 d 时间内结束，结果 Promise 会以 ErrTimeout 被拒绝
+func Timeout(p *Promise, d time.Duration) *Promise {
+	result := &Promise{state: Pending, ctx: p.ctx, upstream: []*Promise{p}, stage: p.stage + 1}
+
+	// 先订阅再启动定时器，已经结束的 p 不会因为定时器先触发而超时
+	var mu sync.Mutex
+	var timer *time.Timer
+
+	p.subscribe(func() {
+		mu.Lock()
+		if timer != nil {
+			timer.Stop()
+		}
+		mu.Unlock()
+
+		state, value, reason := p.snapshot()
+		if state == Cancelled {
+			result.cancel(reason)
+		} else if reason != nil {
+			result.reject(nil, reason)
+		} else {
+			result.resolve(value, nil)
+		}
+	})
+
+	mu.Lock()
+	if result.getState() == Pending {
+		timer = time.AfterFunc(d, func() {
+			result.reject(nil, ErrTimeout)
+		})
+	}
+	mu.Unlock()
+
+	return result
+}
+
+// 以最多 limit 的并发度依次调用 factory 为每个位置创建 Promise，并在每个 Promise 结束时调用 onSettled
+// onSettled 返回 false 时不再创建新的 Promise；limit 小于等于 0 时不限制并发数量
+// 创建的 Promise 会被记录为 result 的上游
+func runLimited(result *Promise, count, limit int, factory func(index int) *Promise, onSettled func(index int, state PromiseState, value interface{}, reason error) bool) {
+	if limit <= 0 || limit > count {
+		limit = count
+	}
+
+	var mu sync.Mutex
+	next := 0
+	isStopped := false
+
+	var launch func()
+	launch = func() {
+		mu.Lock()
+		if isStopped || next >= count {
+			mu.Unlock()
+			return
+		}
+		index := next
+		next++
+		mu.Unlock()
+
+		p := callFactory(func() *Promise { return factory(index) })
+		result.addUpstream(p)
+		p.subscribe(func() {
+			state, value, reason := p.snapshot()
+			if !onSettled(index, state, value, reason) {
+				mu.Lock()
+				isStopped = true
+				mu.Unlock()
+				return
+			}
+			launch()
+		})
+	}
+
+	for i := 0; i < limit; i++ {
+		launch()
+	}
+}
+
+// MapLimit 对 inputs 中的每个元素调用 fn 创建 Promise，同时最多有 limit 个 Promise 处于执行中
+// 结果 Promise 以按输入顺序排列的 []interface{} 完成
+// 任何一个 Promise 被拒绝时，结果 Promise 立即被拒绝，并且不再创建新的 Promise
+// limit 小于等于 0 时不限制并发数量
+func MapLimit(inputs []interface{}, limit int, fn func(interface{}) *Promise) *Promise {
+	if fn == nil {
+		return nil
+	}
+
+	result := &Promise{state: Pending}
+	if len(inputs) == 0 {
+		result.resolve([]interface{}{}, nil)
+		return result
+	}
+
+	var mu sync.Mutex
+	values := make([]interface{}, len(inputs))
+	pendingCount := len(inputs)
+
+	runLimited(result, len(inputs), limit, func(index int) *Promise {
+		return fn(inputs[index])
+	}, func(index int, _ PromiseState, value interface{}, reason error) bool {
+		// 结果 Promise 已经结束（包括被取消），停止创建新的 Promise
+		if result.getState() != Pending {
+			return false
+		}
+		if reason != nil {
+			result.reject(nil, reason)
+			return false
+		}
+
+		mu.Lock()
+		values[index] = value
+		pendingCount--
+		isCompleted := pendingCount == 0
+		mu.Unlock()
+
+		if isCompleted {
+			result.resolve(values, nil)
 		}
+		return true
+	})
+
+	return result
+}
+
+// MapLimitSettled 与 MapLimit 类似，但会处理所有输入，无论其状态如何
+// 结果 Promise 以按输入顺序排列的 []SettledResult 完成
+func MapLimitSettled(inputs []interface{}, limit int, fn func(interface{}) *Promise) *Promise {
+	if fn == nil {
+		return nil
+	}
+
+	result := &Promise{state: Pending}
+	if len(inputs) == 0 {
+		result.resolve([]SettledResult{}, nil)
+		return result
+	}
+
+	var mu sync.Mutex
+	results := make([]SettledResult, len(inputs))
+	pendingCount := len(inputs)
+
+	runLimited(result, len(inputs), limit, func(index int) *Promise {
+		return fn(inputs[index])
+	}, func(index int, state PromiseState, value interface{}, reason error) bool {
+		if result.getState() != Pending {
+			return false
+		}
+
+		mu.Lock()
+		results[index] = newSettledResult(index, state, value, reason)
+		pendingCount--
+		isCompleted := pendingCount == 0
+		mu.Unlock()
+
+		if isCompleted {
+			result.resolve(results, nil)
+		}
+		return true
+	})
+
+	return result
+}
+
+// 从 initial 开始依次调用 factory 创建 Promise，每个 Promise 的值作为下一次调用的 prev
+// 任何一个 Promise 被拒绝时，结果 Promise 立即被拒绝；全部完成时，结果 Promise 以最后一个值完成
+func runSeries(initial interface{}, count int, factory func(index int, prev interface{}) *Promise) *Promise {
+	result := &Promise{state: Pending}
+
+	var step func(index int, prev interface{})
+	step = func(index int, prev interface{}) {
+		// 结果 Promise 已被取消，不再继续执行
+		if result.getState() != Pending {
+			return
+		}
+		if index >= count {
+			result.resolve(prev, nil)
+			return
+		}
+
+		p := callFactory(func() *Promise { return factory(index, prev) })
+		result.addUpstream(p)
+		p.subscribe(func() {
+			_, value, reason := p.snapshot()
+			if reason != nil {
+				result.reject(nil, reason)
+				return
+			}
+			step(index+1, value)
+		})
+	}
+
+	step(0, initial)
+
+	return result
+}
+
+// Series 依次执行 Promise 工厂函数，每个工厂函数接收上一个 Promise 的值（第一个接收 nil）
+// 任何一个 Promise 被拒绝时，结果 Promise 立即被拒绝，后续工厂函数不会被调用
+// 全部完成时，结果 Promise 以最后一个 Promise 的值完成
+func Series(factories ...func(prev interface{}) *Promise) *Promise {
+	return runSeries(nil, len(factories), func(index int, prev interface{}) *Promise {
+		return factories[index](prev)
+	})
+}
+
+// Reduce 依次对 inputs 中的每个元素调用 fn，fn 接收累积值和当前元素并返回新累积值的 Promise
+// 累积值从 initial 开始，结果 Promise 以最终的累积值完成
+// 任何一个 Promise 被拒绝时，结果 Promise 立即被拒绝
+func Reduce(inputs []interface{}, initial interface{}, fn func(acc interface{}, value interface{}) *Promise) *Promise {
+	if fn == nil {
+		return nil
+	}
+
+	return runSeries(initial, len(inputs), func(index int, acc interface{}) *Promise {
+		return fn(acc, inputs[index])
 	})
 }
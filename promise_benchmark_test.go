@@ -168,7 +168,7 @@ func BenchmarkPromiseAllSettled(b *testing.B) {
 			for i := 0; i < b.N; i++ {
 				p := AllSettled(promises...)
 				if i == 0 {
-					values, ok := p.GetValue().([]interface{})
+					values, ok := p.GetValue().([]SettledResult)
 					if !ok || p.GetReason() != nil || len(values) != size {
 						b.Fatalf("unexpected AllSettled result: value=%v reason=%v", p.GetValue(), p.GetReason())
 					}
@@ -1,10 +1,12 @@
 package vowlink
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -21,6 +23,7 @@ func TestPromise_Then(t *testing.T) {
 			return value.(string) + " vowlink", nil
 		}, nil)
 
+		waitSettled(t, result)
 		assert.Equal(t, Fulfilled, result.state, "Expected state to be Fulfilled")
 		assert.Equal(t, "Hello, World! vowlink", result.value, "Expected value to be 'Hello, World! vowlink'")
 	})
@@ -34,6 +37,7 @@ func TestPromise_Then(t *testing.T) {
 			return nil, errors.New("Handled error: " + reason.Error())
 		})
 
+		waitSettled(t, result)
 		assert.Equal(t, Rejected, result.state, "Expected state to be Rejected")
 		assert.Equal(t, "Handled error: Something went wrong", result.reason.Error(), "Expected reason to be 'Handled error: Something went wrong'")
 	})
@@ -45,6 +49,7 @@ func TestPromise_Then(t *testing.T) {
 
 		result := p.Then(nil, nil)
 
+		waitSettled(t, result)
 		assert.Equal(t, Fulfilled, result.state, "Expected state to be Fulfilled")
 		assert.Equal(t, "Hello, World!", result.value, "Expected value to be 'Hello, World!'")
 	})
@@ -60,6 +65,7 @@ func TestPromise_Then(t *testing.T) {
 			return value.(string) + "!", nil
 		}, nil)
 
+		waitSettled(t, result)
 		assert.Equal(t, Fulfilled, result.state, "Expected state to be Fulfilled")
 		assert.Equal(t, "Hello, World! vowlink!", result.value, "Expected value to be 'Hello, World! vowlink!'")
 	})
@@ -78,6 +84,7 @@ func TestPromise_Then(t *testing.T) {
 			return nil, errors.New("Handled error: " + reason.Error())
 		})
 
+		waitSettled(t, result)
 		assert.Equal(t, Fulfilled, result.state, "Expected state to be Fulfilled")
 		assert.Equal(t, "Hello, World! vowlink!", result.value, "Expected value to be 'Hello, World! vowlink!'")
 	})
@@ -93,8 +100,9 @@ func TestPromise_Then(t *testing.T) {
 			}), nil
 		}, nil)
 
+		waitSettled(t, result)
 		assert.Equal(t, Fulfilled, result.state, "Expected state to be Fulfilled")
-		assert.Equal(t, "Hello, World! vowlink", result.value.(*Promise).GetValue(), "Expected value to be 'Hello, World! vowlink'")
+		assert.Equal(t, "Hello, World! vowlink", result.value, "Expected value to be 'Hello, World! vowlink'")
 	})
 
 	t.Run("Then return a Promise with reject", func(t *testing.T) {
@@ -108,9 +116,10 @@ func TestPromise_Then(t *testing.T) {
 			}), nil
 		}, nil)
 
-		assert.Equal(t, Fulfilled, result.state, "Expected state to be Fulfilled")
-		assert.Equal(t, Rejected, result.value.(*Promise).state, "Expected value to be Rejected")
-		assert.Equal(t, "Something went wrong", result.value.(*Promise).GetReason().Error(), "Expected reason to be 'Something went wrong'")
+		waitSettled(t, result)
+		assert.Equal(t, Rejected, result.state, "Expected state to be Rejected")
+		assert.Nil(t, result.value, "Expected value to be nil")
+		assert.Equal(t, "Something went wrong", result.reason.Error(), "Expected reason to be 'Something went wrong'")
 	})
 
 	t.Run("One Then onRejected after Then return a Promise with reject", func(t *testing.T) {
@@ -126,8 +135,9 @@ func TestPromise_Then(t *testing.T) {
 			return nil, errors.New("Handled error: " + reason.Error())
 		})
 
-		assert.Equal(t, Fulfilled, result.state, "Expected state to be Fulfilled")
-		assert.Equal(t, "Something went wrong", result.value.(*Promise).reason.Error(), "Expected reason to be 'Something went wrong', Then(nil, func(reason error) error) not work")
+		waitSettled(t, result)
+		assert.Equal(t, Rejected, result.state, "Expected state to be Rejected")
+		assert.Equal(t, "Handled error: Something went wrong", result.reason.Error(), "Expected reason to be 'Handled error: Something went wrong'")
 	})
 }
 
@@ -141,6 +151,7 @@ func TestPromise_Catch(t *testing.T) {
 			return nil, errors.New("Handled error: " + reason.Error())
 		})
 
+		waitSettled(t, result)
 		assert.Equal(t, Fulfilled, result.state, "Expected state to be Fulfilled")
 		assert.Equal(t, "Hello, World!", result.value, "Expected value to be 'Hello, World!'")
 	})
@@ -154,6 +165,7 @@ func TestPromise_Catch(t *testing.T) {
 			return nil, errors.New("Handled error: " + reason.Error())
 		})
 
+		waitSettled(t, result)
 		assert.Equal(t, Rejected, result.state, "Expected state to be Fulfilled")
 		assert.Equal(t, "Handled error: Something went wrong", result.reason.Error(), "Expected value to be 'Handled error: Something went wrong'")
 	})
@@ -165,6 +177,7 @@ func TestPromise_Catch(t *testing.T) {
 
 		result := p.Catch(nil)
 
+		waitSettled(t, result)
 		assert.Equal(t, Rejected, result.state, "Expected state to be Fulfilled")
 		assert.Equal(t, "Something went wrong", result.reason.Error(), "Expected value to be 'Something went wrong'")
 	})
@@ -182,6 +195,7 @@ func TestPromise_Finally(t *testing.T) {
 			return nil
 		})
 
+		waitSettled(t, result)
 		assert.Equal(t, Fulfilled, result.state, "Expected state to be Fulfilled")
 		assert.Equal(t, "Hello, World!", result.value, "Expected value to be 'Hello, World!'")
 		assert.True(t, finallyCalled, "Expected finally function to be called")
@@ -198,6 +212,7 @@ func TestPromise_Finally(t *testing.T) {
 			return nil
 		})
 
+		waitSettled(t, result)
 		assert.Equal(t, Rejected, result.state, "Expected state to be Rejected")
 		assert.Equal(t, "Something went wrong", result.reason.Error(), "Expected reason to be 'Something went wrong'")
 		assert.True(t, finallyCalled, "Expected finally function to be called")
@@ -210,6 +225,7 @@ func TestPromise_Finally(t *testing.T) {
 
 		result := p.Finally(nil)
 
+		waitSettled(t, result)
 		assert.Equal(t, Fulfilled, result.state, "Expected state to be Fulfilled")
 		assert.Equal(t, "Hello, World!", result.value, "Expected value to be 'Hello, World!'")
 	})
@@ -409,7 +425,11 @@ func TestPromise_AllSettled(t *testing.T) {
 		result := AllSettled(p1, p2, p3)
 
 		assert.Equal(t, Fulfilled, result.state, "Expected state to be Fulfilled")
-		assert.Equal(t, []interface{}{"Promise 1", "Promise 2", "Promise 3"}, result.value, "Expected value to be ['Promise 1', 'Promise 2', 'Promise 3']")
+		assert.Equal(t, []SettledResult{
+			{State: Fulfilled, Value: "Promise 1", Index: 0},
+			{State: Fulfilled, Value: "Promise 2", Index: 1},
+			{State: Fulfilled, Value: "Promise 3", Index: 2},
+		}, result.value, "Expected all results to be Fulfilled")
 	})
 
 	t.Run("One promise rejected", func(t *testing.T) {
@@ -428,7 +448,11 @@ func TestPromise_AllSettled(t *testing.T) {
 		result := AllSettled(p1, p2, p3)
 
 		assert.Equal(t, Fulfilled, result.state, "Expected state to be Fulfilled")
-		assert.Equal(t, []interface{}{"Promise 1", errors.New("Promise 2 rejected"), "Promise 3"}, result.value, "Expected value to be ['Promise 1', errors.New('Promise 2 rejected'), 'Promise 3']")
+		assert.Equal(t, []SettledResult{
+			{State: Fulfilled, Value: "Promise 1", Index: 0},
+			{State: Rejected, Reason: errors.New("Promise 2 rejected"), Index: 1},
+			{State: Fulfilled, Value: "Promise 3", Index: 2},
+		}, result.value, "Expected the second result to be Rejected")
 	})
 
 	t.Run("All promises rejected", func(t *testing.T) {
@@ -447,7 +471,48 @@ func TestPromise_AllSettled(t *testing.T) {
 		result := AllSettled(p1, p2, p3)
 
 		assert.Equal(t, Fulfilled, result.state, "Expected state to be Fulfilled")
-		assert.Equal(t, []interface{}{errors.New("Promise 1 rejected"), errors.New("Promise 2 rejected"), errors.New("Promise 3 rejected")}, result.value, "Expected value to be [errors.New('Promise 1 rejected'), errors.New('Promise 2 rejected'), errors.New('Promise 3 rejected')]")
+		assert.Equal(t, []SettledResult{
+			{State: Rejected, Reason: errors.New("Promise 1 rejected"), Index: 0},
+			{State: Rejected, Reason: errors.New("Promise 2 rejected"), Index: 1},
+			{State: Rejected, Reason: errors.New("Promise 3 rejected"), Index: 2},
+		}, result.value, "Expected all results to be Rejected")
+	})
+
+	t.Run("Promise resolved with error data", func(t *testing.T) {
+		p1 := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve(errors.New("Something went wrong"), nil)
+		})
+
+		result := AllSettled(p1)
+		results := result.value.([]SettledResult)
+
+		assert.True(t, results[0].IsFulfilled(), "Expected result to be Fulfilled")
+		assert.Nil(t, results[0].Reason, "Expected reason to be nil")
+		assert.Equal(t, errors.New("Something went wrong"), results[0].Value, "Expected value to be the error data")
+	})
+
+	t.Run("Partition results", func(t *testing.T) {
+		p1 := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("Promise 1", nil)
+		})
+
+		p2 := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			reject(nil, errors.New("Promise 2 rejected"))
+		})
+
+		p3 := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("Promise 3", nil)
+		})
+
+		fulfilled, rejected := PartitionSettledResults(AllSettled(p1, p2, p3).GetValue().([]SettledResult))
+
+		assert.Equal(t, []SettledResult{
+			{State: Fulfilled, Value: "Promise 1", Index: 0},
+			{State: Fulfilled, Value: "Promise 3", Index: 2},
+		}, fulfilled, "Expected fulfilled results to keep input order")
+		assert.Equal(t, []SettledResult{
+			{State: Rejected, Reason: errors.New("Promise 2 rejected"), Index: 1},
+		}, rejected, "Expected rejected results to keep input order")
 	})
 }
 
@@ -463,6 +528,7 @@ func TestPromise_MultiCatch(t *testing.T) {
 			return nil, errors.New("Handled 3 error: " + reason.Error())
 		})
 
+		waitSettled(t, p)
 		assert.Equal(t, "Handled 3 error: Handled 2 error: Handled 1 error: Something went wrong", p.GetReason().Error(), "Expected reason to be 'Handled 3 error: Handled 2 error: Handled 1 error: Something went wrong'")
 		assert.Nil(t, p.GetValue(), "Expected value to be nil")
 	})
@@ -478,6 +544,7 @@ func TestPromise_MultiCatch(t *testing.T) {
 			return data, nil
 		}, nil)
 
+		waitSettled(t, p)
 		assert.Equal(t, "Recovered value", p.GetValue().(string), "Expected value to be 'Recovered value'")
 		assert.Nil(t, p.GetReason(), "Expected reason to be nil")
 	})
@@ -495,6 +562,7 @@ func TestPromise_MultiCatch(t *testing.T) {
 			return nil, errors.New("Handled 2 error: " + reason.Error())
 		})
 
+		waitSettled(t, p)
 		assert.Equal(t, "Handled 2 error: Then error: Recovered value", p.GetReason().Error(), "Expected reason to be 'Handled 2 error: Then error: Recovered value'")
 		assert.Nil(t, p.GetValue(), "Expected value to be nil")
 	})
@@ -513,6 +581,7 @@ func TestPromise_MultiCatch(t *testing.T) {
 			return nil, errors.New("Handled 3 error: " + reason.Error())
 		})
 
+		waitSettled(t, p)
 		assert.Equal(t, "Handled 3 error: Handled 2 error: Handled 1 error: Something went wrong", p.GetReason().Error(), "Expected reason to be 'Handled 3 error: Handled 2 error: Handled 1 error: Something went wrong'")
 		assert.Nil(t, p.GetValue(), "Expected value to be nil")
 	})
@@ -531,6 +600,7 @@ func TestPromise_MultiCatch(t *testing.T) {
 			return data, nil
 		}, nil)
 
+		waitSettled(t, p)
 		assert.Equal(t, "Recovered value", p.GetValue().(string), "Expected value to be 'Recovered value'")
 		assert.Nil(t, p.GetReason(), "Expected reason to be nil")
 	})
@@ -550,6 +620,7 @@ func TestPromise_ResolveWithError(t *testing.T) {
 		return data, nil
 	}, nil)
 
+	waitSettled(t, p)
 	assert.Equal(t, "Recovered value", p.GetValue().(string), "Expected value to be 'Recovered value'")
 	assert.Nil(t, p.GetReason(), "Expected reason to be nil")
 }
@@ -565,6 +636,7 @@ func TestPromise_ResolveWithErrorData(t *testing.T) {
 		return fmt.Sprintf("Recovered value: %v", reason.Error()), nil
 	})
 
+	waitSettled(t, p)
 	assert.Equal(t, "Something went wrong", p.GetValue().(string), "Expected value to be 'Something went wrong'")
 	assert.Nil(t, p.GetReason(), "Expected reason to be nil")
 }
@@ -580,6 +652,7 @@ func TestPromise_RejectWithNil(t *testing.T) {
 		return fmt.Sprintf("Recovered value: %v", reason.Error()), nil
 	})
 
+	waitSettled(t, p)
 	assert.Equal(t, "Something went wrong", p.GetValue().(string), "Expected reason to be 'Something went wrong'")
 	assert.Nil(t, p.GetReason(), "Expected value to be nil")
 }
@@ -596,6 +669,7 @@ func TestPromise_FinallyWithError(t *testing.T) {
 			return nil, errors.New("Handled error: " + reason.Error())
 		})
 
+		waitSettled(t, p)
 		assert.Equal(t, "Handled error: Finally error", p.GetReason().Error(), "Expected reason to be 'Handled error: Finally error'")
 		assert.Nil(t, p.GetValue(), "Expected value to be nil")
 
@@ -612,6 +686,7 @@ func TestPromise_FinallyWithError(t *testing.T) {
 			return nil, errors.New("Handled error: " + reason.Error())
 		})
 
+		waitSettled(t, p)
 		assert.Equal(t, "Handled error: Finally error", p.GetReason().Error(), "Expected reason to be 'Handled error: Finally error'")
 		assert.Nil(t, p.GetValue(), "Expected value to be nil")
 	})
@@ -752,6 +827,1329 @@ func TestPromise_EmptyArray(t *testing.T) {
 	t.Run("AllSettled with empty array", func(t *testing.T) {
 		result := AllSettled()
 		assert.Equal(t, Fulfilled, result.state)
-		assert.Equal(t, []interface{}{}, result.value)
+		assert.Equal(t, []SettledResult{}, result.value)
+	})
+}
+
+func TestPromise_DeferredSettlement(t *testing.T) {
+	t.Run("Then on pending promise resolved later", func(t *testing.T) {
+		var resolveLater func(interface{}, error)
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolveLater = resolve
+		})
+
+		result := p.Then(func(value interface{}) (interface{}, error) {
+			return value.(string) + " vowlink", nil
+		}, nil)
+
+		assert.Equal(t, Pending, result.getState(), "Expected state to be Pending before settlement")
+
+		resolveLater("Hello, World!", nil)
+
+		waitSettled(t, result)
+		assert.Equal(t, Fulfilled, result.getState(), "Expected state to be Fulfilled")
+		assert.Equal(t, "Hello, World! vowlink", result.GetValue(), "Expected value to be 'Hello, World! vowlink'")
+	})
+
+	t.Run("Catch and Finally on pending promise rejected from another goroutine", func(t *testing.T) {
+		var wg sync.WaitGroup
+		wg.Add(1)
+
+		var rejectLater func(interface{}, error)
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			rejectLater = reject
+		})
+
+		var finallyCalled bool
+		result := p.Catch(func(reason error) (interface{}, error) {
+			return nil, errors.New("Handled error: " + reason.Error())
+		}).Finally(func() error {
+			finallyCalled = true
+			return nil
+		})
+
+		go func() {
+			defer wg.Done()
+			rejectLater(nil, errors.New("Something went wrong"))
+		}()
+
+		wg.Wait()
+
+		waitSettled(t, result)
+		assert.Equal(t, Rejected, result.getState(), "Expected state to be Rejected")
+		assert.Equal(t, "Handled error: Something went wrong", result.GetReason().Error(), "Expected reason to be 'Handled error: Something went wrong'")
+		assert.True(t, finallyCalled, "Expected finally function to be called")
+	})
+
+	t.Run("Multiple handlers on the same pending promise", func(t *testing.T) {
+		var resolveLater func(interface{}, error)
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolveLater = resolve
+		})
+
+		r1 := p.Then(func(value interface{}) (interface{}, error) {
+			return value.(int) + 1, nil
+		}, nil)
+		r2 := p.Then(func(value interface{}) (interface{}, error) {
+			return value.(int) + 2, nil
+		}, nil)
+
+		resolveLater(1, nil)
+
+		waitSettled(t, r1)
+		assert.Equal(t, 2, r1.GetValue(), "Expected value to be 2")
+		waitSettled(t, r2)
+		assert.Equal(t, 3, r2.GetValue(), "Expected value to be 3")
+	})
+}
+
+func TestPromise_Await(t *testing.T) {
+	t.Run("Settled promise", func(t *testing.T) {
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("Hello, World!", nil)
+		})
+
+		value, err := p.Await(context.Background())
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, "Hello, World!", value, "Expected value to be 'Hello, World!'")
+	})
+
+	t.Run("Rejected promise", func(t *testing.T) {
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			reject(nil, errors.New("Something went wrong"))
+		})
+
+		value, err := p.Await(context.Background())
+
+		assert.Nil(t, value, "Expected value to be nil")
+		assert.Equal(t, "Something went wrong", err.Error(), "Expected error to be 'Something went wrong'")
+	})
+
+	t.Run("Pending promise resolved from another goroutine", func(t *testing.T) {
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				resolve(nil, nil)
+			}()
+		})
+
+		value, err := p.Await(context.Background())
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Nil(t, value, "Expected value to be nil")
+		assert.Equal(t, Fulfilled, p.getState(), "Expected state to be Fulfilled")
+	})
+
+	t.Run("Context cancelled before settlement", func(t *testing.T) {
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		value, err := p.Await(ctx)
+
+		assert.Nil(t, value, "Expected value to be nil")
+		assert.Equal(t, context.DeadlineExceeded, err, "Expected error to be context.DeadlineExceeded")
+		assert.Equal(t, Pending, p.getState(), "Expected state to be Pending")
+	})
+}
+
+func TestPromise_Done(t *testing.T) {
+	t.Run("Settled promise", func(t *testing.T) {
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("Hello, World!", nil)
+		})
+
+		select {
+		case <-p.Done():
+		default:
+			t.Fatal("Expected done channel to be closed")
+		}
+	})
+
+	t.Run("Pending promise", func(t *testing.T) {
+		var rejectLater func(interface{}, error)
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			rejectLater = reject
+		})
+
+		done := p.Done()
+		assert.Equal(t, done, p.Done(), "Expected the same done channel")
+
+		select {
+		case <-done:
+			t.Fatal("Expected done channel to be open")
+		default:
+		}
+
+		go rejectLater(nil, errors.New("Something went wrong"))
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Expected done channel to be closed")
+		}
+
+		assert.Equal(t, Rejected, p.getState(), "Expected state to be Rejected")
+		assert.Equal(t, "Something went wrong", p.GetReason().Error(), "Expected reason to be 'Something went wrong'")
+	})
+}
+
+func TestNewPromiseAsync(t *testing.T) {
+	t.Run("nil handler", func(t *testing.T) {
+		p := NewPromiseAsync(nil)
+		assert.Nil(t, p, "Expected nil when handler is nil")
+	})
+
+	t.Run("handler runs on another goroutine", func(t *testing.T) {
+		release := make(chan struct{})
+		p := NewPromiseAsync(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			<-release
+			resolve("Hello, World!", nil)
+		})
+
+		assert.Equal(t, Pending, p.getState(), "Expected state to be Pending")
+
+		result := p.Then(func(value interface{}) (interface{}, error) {
+			return value.(string) + " vowlink", nil
+		}, nil)
+
+		close(release)
+
+		value, err := result.Await(context.Background())
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, "Hello, World! vowlink", value, "Expected value to be 'Hello, World! vowlink'")
+	})
+
+	t.Run("handler rejects", func(t *testing.T) {
+		p := NewPromiseAsync(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			reject(nil, errors.New("Something went wrong"))
+		})
+
+		_, err := p.Await(context.Background())
+		assert.Equal(t, "Something went wrong", err.Error(), "Expected error to be 'Something went wrong'")
+	})
+}
+
+func TestNewPromiseWithContext(t *testing.T) {
+	t.Run("nil handler", func(t *testing.T) {
+		p := NewPromiseWithContext(context.Background(), nil)
+		assert.Nil(t, p, "Expected nil when handler is nil")
+	})
+
+	t.Run("Resolved before cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p := NewPromiseWithContext(ctx, func(ctx context.Context, resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("Hello, World!", nil)
+		})
+
+		cancel()
+
+		assert.Equal(t, Fulfilled, p.getState(), "Expected state to be Fulfilled")
+		assert.Equal(t, "Hello, World!", p.GetValue(), "Expected value to be 'Hello, World!'")
+	})
+
+	t.Run("Context already cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var handlerCalled bool
+		p := NewPromiseWithContext(ctx, func(ctx context.Context, resolve func(interface{}, error), reject func(interface{}, error)) {
+			handlerCalled = true
+		})
+
+		assert.False(t, handlerCalled, "Expected handler not to be called")
+		assert.Equal(t, Rejected, p.getState(), "Expected state to be Rejected")
+		assert.Equal(t, context.Canceled, p.GetReason(), "Expected reason to be context.Canceled")
+	})
+
+	t.Run("Cancellation propagates through the chain", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		p := NewPromiseWithContext(ctx, func(ctx context.Context, resolve func(interface{}, error), reject func(interface{}, error)) {})
+
+		var thenCalled, finallyCalled bool
+		result := p.Then(func(value interface{}) (interface{}, error) {
+			thenCalled = true
+			return value, nil
+		}, nil).Finally(func() error {
+			finallyCalled = true
+			return nil
+		})
+
+		cancel()
+
+		value, err := result.Await(context.Background())
+		assert.Nil(t, value, "Expected value to be nil")
+		assert.Equal(t, context.Canceled, err, "Expected error to be context.Canceled")
+		assert.False(t, thenCalled, "Expected then function not to be called")
+		assert.True(t, finallyCalled, "Expected finally function to be called")
+	})
+
+	t.Run("Derived promise skips success handlers after cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		p := NewPromiseWithContext(ctx, func(ctx context.Context, resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("Hello, World!", nil)
+		})
+
+		cancel()
+
+		result := p.Then(func(value interface{}) (interface{}, error) {
+			return value.(string) + " vowlink", nil
+		}, nil).Catch(func(reason error) (interface{}, error) {
+			return nil, errors.New("Handled error: " + reason.Error())
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, Rejected, result.getState(), "Expected state to be Rejected")
+		assert.Equal(t, "Handled error: context canceled", result.GetReason().Error(), "Expected reason to be 'Handled error: context canceled'")
+	})
+}
+
+func TestPromise_Timeout(t *testing.T) {
+	t.Run("Settled before timeout", func(t *testing.T) {
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("Hello, World!", nil)
+		})
+
+		result := Timeout(p, time.Second)
+
+		assert.Equal(t, Fulfilled, result.getState(), "Expected state to be Fulfilled")
+		assert.Equal(t, "Hello, World!", result.GetValue(), "Expected value to be 'Hello, World!'")
+	})
+
+	t.Run("Already settled with a zero timeout", func(t *testing.T) {
+		fulfilled := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("Hello, World!", nil)
+		})
+		rejected := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			reject(nil, errors.New("Something went wrong"))
+		})
+
+		for i := 0; i < 1000; i++ {
+			assert.Equal(t, Fulfilled, Timeout(fulfilled, 0).getState(), "Expected a fulfilled input not to time out")
+			assert.Equal(t, "Something went wrong", Timeout(rejected, 0).GetReason().Error(), "Expected a rejected input to keep its reason")
+		}
+	})
+
+	t.Run("Rejected before timeout", func(t *testing.T) {
+		p := NewPromiseAsync(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			reject(nil, errors.New("Something went wrong"))
+		})
+
+		_, err := p.WithTimeout(time.Second).Await(context.Background())
+
+		assert.Equal(t, "Something went wrong", err.Error(), "Expected error to be 'Something went wrong'")
+	})
+
+	t.Run("Pending promise times out", func(t *testing.T) {
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+
+		value, err := p.WithTimeout(10 * time.Millisecond).Await(context.Background())
+
+		assert.Nil(t, value, "Expected value to be nil")
+		assert.Equal(t, ErrTimeout, err, "Expected error to be ErrTimeout")
+		assert.Equal(t, Pending, p.getState(), "Expected source state to be Pending")
+	})
+
+	t.Run("Timeout with Race", func(t *testing.T) {
+		slow := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+		fast := NewPromiseAsync(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("fast", nil)
+		})
+
+		value, err := Race(slow, fast).WithTimeout(time.Second).Await(context.Background())
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, "fast", value, "Expected value to be 'fast'")
+	})
+}
+
+func TestPromise_ConcurrentCombinators(t *testing.T) {
+	const size = 64
+
+	// 创建在各自 goroutine 中结束的 Promise，第 i 个 Promise 在 failAt 处被拒绝
+	makePromises := func(failAt map[int]bool) []*Promise {
+		promises := make([]*Promise, size)
+		for i := 0; i < size; i++ {
+			i := i
+			promises[i] = NewPromiseAsync(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				time.Sleep(time.Duration(i%4) * time.Millisecond)
+				if failAt[i] {
+					reject(nil, fmt.Errorf("Promise %d rejected", i))
+				} else {
+					resolve(i, nil)
+				}
+			})
+		}
+		return promises
+	}
+
+	t.Run("All with concurrent settlement", func(t *testing.T) {
+		value, err := All(makePromises(nil)...).Await(context.Background())
+
+		assert.Nil(t, err, "Expected error to be nil")
+		values := value.([]interface{})
+		for i := 0; i < size; i++ {
+			assert.Equal(t, i, values[i], "Expected values in input order")
+		}
+	})
+
+	t.Run("All with concurrent rejection", func(t *testing.T) {
+		_, err := All(makePromises(map[int]bool{10: true, 20: true})...).Await(context.Background())
+
+		assert.NotNil(t, err, "Expected error to be set")
+	})
+
+	t.Run("AllSettled with concurrent settlement", func(t *testing.T) {
+		failAt := map[int]bool{1: true, 7: true, 33: true}
+		value, err := AllSettled(makePromises(failAt)...).Await(context.Background())
+
+		assert.Nil(t, err, "Expected error to be nil")
+		results := value.([]SettledResult)
+		for i := 0; i < size; i++ {
+			assert.Equal(t, i, results[i].Index, "Expected results in input order")
+			assert.Equal(t, failAt[i], results[i].IsRejected(), "Expected result state to match input")
+		}
+	})
+
+	t.Run("Any with concurrent rejection", func(t *testing.T) {
+		failAt := make(map[int]bool, size)
+		for i := 0; i < size; i++ {
+			failAt[i] = true
+		}
+
+		_, err := Any(makePromises(failAt)...).Await(context.Background())
+
+		assert.IsType(t, &AggregateError{}, err, "Expected error to be an AggregateError")
+		assert.Len(t, err.(*AggregateError).Errors, size, "Expected all errors to be collected")
+	})
+
+	t.Run("Any with concurrent settlement", func(t *testing.T) {
+		value, err := Any(makePromises(map[int]bool{0: true})...).Await(context.Background())
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.NotNil(t, value, "Expected value to be set")
+	})
+
+	t.Run("Race with concurrent settlement", func(t *testing.T) {
+		_, err := Race(makePromises(map[int]bool{5: true})...).Await(context.Background())
+		value, _ := Race(makePromises(nil)...).Await(context.Background())
+
+		assert.True(t, err == nil || err.Error() == "Promise 5 rejected", "Expected either a value or the rejection")
+		assert.IsType(t, 0, value, "Expected value to be an input value")
+	})
+}
+
+func TestPromise_Cancel(t *testing.T) {
+	t.Run("Cancel pending promise", func(t *testing.T) {
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+
+		p.Cancel(nil)
+
+		assert.Equal(t, Cancelled, p.getState(), "Expected state to be Cancelled")
+		assert.Equal(t, ErrCancelled, p.GetReason(), "Expected reason to be ErrCancelled")
+		assert.Nil(t, p.GetValue(), "Expected value to be nil")
+	})
+
+	t.Run("Cancel settled promise", func(t *testing.T) {
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("Hello, World!", nil)
+		})
+
+		p.Cancel(errors.New("user cancelled"))
+
+		assert.Equal(t, Fulfilled, p.getState(), "Expected state to be Fulfilled")
+		assert.Equal(t, "Hello, World!", p.GetValue(), "Expected value to be 'Hello, World!'")
 	})
+
+	t.Run("Cancellation propagates to derived chain", func(t *testing.T) {
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+
+		var thenCalled, catchCalled, finallyCalled bool
+		result := p.Then(func(value interface{}) (interface{}, error) {
+			thenCalled = true
+			return value, nil
+		}, nil).Catch(func(reason error) (interface{}, error) {
+			catchCalled = true
+			return nil, reason
+		}).Finally(func() error {
+			finallyCalled = true
+			return nil
+		})
+
+		reason := errors.New("user cancelled")
+		p.Cancel(reason)
+
+		waitSettled(t, result)
+		assert.Equal(t, Cancelled, result.getState(), "Expected state to be Cancelled")
+		assert.Equal(t, reason, result.GetReason(), "Expected reason to be 'user cancelled'")
+		assert.False(t, thenCalled, "Expected then function not to be called")
+		assert.False(t, catchCalled, "Expected catch function not to be called")
+		assert.True(t, finallyCalled, "Expected finally function to be called")
+	})
+
+	t.Run("Then on cancelled promise", func(t *testing.T) {
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+		p.Cancel(nil)
+
+		result := p.Catch(func(reason error) (interface{}, error) {
+			return "Recovered value", nil
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, Cancelled, result.getState(), "Expected state to be Cancelled")
+		assert.Equal(t, ErrCancelled, result.GetReason(), "Expected reason to be ErrCancelled")
+	})
+
+	t.Run("Await cancelled promise", func(t *testing.T) {
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+
+		go p.Cancel(nil)
+
+		_, err := p.Await(context.Background())
+		assert.Equal(t, ErrCancelled, err, "Expected error to be ErrCancelled")
+	})
+
+	t.Run("Combinators treat cancelled input as rejected", func(t *testing.T) {
+		p1 := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("Promise 1", nil)
+		})
+		p2 := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+
+		all := All(p1, p2)
+		settled := AllSettled(p1, p2)
+
+		p2.Cancel(nil)
+
+		assert.Equal(t, Rejected, all.getState(), "Expected state to be Rejected")
+		assert.Equal(t, ErrCancelled, all.GetReason(), "Expected reason to be ErrCancelled")
+		assert.Equal(t, []SettledResult{
+			{State: Fulfilled, Value: "Promise 1", Index: 0},
+			{State: Cancelled, Reason: ErrCancelled, Index: 1},
+		}, settled.GetValue(), "Expected the second result to be Cancelled")
+	})
+
+	t.Run("Cancel upstream combinator inputs", func(t *testing.T) {
+		p1 := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+		p2 := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("Promise 2", nil)
+		})
+		p3 := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+
+		result := All(p1, p2, p3).Then(func(value interface{}) (interface{}, error) {
+			return value, nil
+		}, nil)
+
+		result.CancelUpstream(nil)
+
+		waitSettled(t, result)
+		assert.Equal(t, Cancelled, result.getState(), "Expected state to be Cancelled")
+		assert.Equal(t, Cancelled, p1.getState(), "Expected input 1 to be Cancelled")
+		assert.Equal(t, Fulfilled, p2.getState(), "Expected input 2 to stay Fulfilled")
+		assert.Equal(t, Cancelled, p3.getState(), "Expected input 3 to be Cancelled")
+	})
+
+	t.Run("Cancel does not propagate upstream", func(t *testing.T) {
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+
+		result := Race(p)
+		result.Cancel(nil)
+
+		assert.Equal(t, Cancelled, result.getState(), "Expected state to be Cancelled")
+		assert.Equal(t, Pending, p.getState(), "Expected input to stay Pending")
+	})
+}
+
+func TestPromise_PanicRecovery(t *testing.T) {
+	t.Run("Panic in promise handler", func(t *testing.T) {
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			panic("Something went wrong")
+		})
+
+		assert.Equal(t, Rejected, p.getState(), "Expected state to be Rejected")
+		assert.IsType(t, &PanicError{}, p.GetReason(), "Expected reason to be a PanicError")
+		assert.Equal(t, "Something went wrong", p.GetReason().(*PanicError).Value, "Expected panic value to be 'Something went wrong'")
+		assert.NotEmpty(t, p.GetReason().(*PanicError).Stack, "Expected stack trace to be recorded")
+	})
+
+	t.Run("Panic after resolve keeps the settled state", func(t *testing.T) {
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("Hello, World!", nil)
+			panic("Something went wrong")
+		})
+
+		assert.Equal(t, Fulfilled, p.getState(), "Expected state to be Fulfilled")
+		assert.Equal(t, "Hello, World!", p.GetValue(), "Expected value to be 'Hello, World!'")
+	})
+
+	t.Run("Panic in async promise handler", func(t *testing.T) {
+		p := NewPromiseAsync(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			panic(errors.New("Something went wrong"))
+		})
+
+		_, err := p.Await(context.Background())
+
+		var panicErr *PanicError
+		assert.True(t, errors.As(err, &panicErr), "Expected error to be a PanicError")
+		assert.Equal(t, "Something went wrong", errors.Unwrap(err).Error(), "Expected PanicError to unwrap the panic error")
+	})
+
+	t.Run("Panic in success handler", func(t *testing.T) {
+		result := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve(42, nil)
+		}).Then(func(value interface{}) (interface{}, error) {
+			return value.(string), nil
+		}, nil).Catch(func(reason error) (interface{}, error) {
+			_, ok := reason.(*PanicError)
+			return ok, nil
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, true, result.GetValue(), "Expected catch function to receive a PanicError")
+	})
+
+	t.Run("Panic in error handler", func(t *testing.T) {
+		result := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			reject(nil, errors.New("Something went wrong"))
+		}).Catch(func(reason error) (interface{}, error) {
+			panic("Handled error: " + reason.Error())
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, Rejected, result.getState(), "Expected state to be Rejected")
+		assert.Equal(t, "promise handler panicked: Handled error: Something went wrong", result.GetReason().Error(), "Expected reason to describe the panic")
+	})
+
+	t.Run("Panic in cleanup handler", func(t *testing.T) {
+		result := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("Hello, World!", nil)
+		}).Finally(func() error {
+			panic("Something went wrong")
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, Rejected, result.getState(), "Expected state to be Rejected")
+		assert.IsType(t, &PanicError{}, result.GetReason(), "Expected reason to be a PanicError")
+	})
+
+	t.Run("Panic recovery disabled", func(t *testing.T) {
+		SetPanicRecovery(false)
+		defer SetPanicRecovery(true)
+
+		assert.Panics(t, func() {
+			NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				panic("Something went wrong")
+			})
+		}, "Expected panic to propagate")
+	})
+}
+
+func TestMapLimit(t *testing.T) {
+	// 创建记录并发峰值的工厂函数，输入为 "fail" 时被拒绝
+	makeFactory := func(running, peak *int32) func(interface{}) *Promise {
+		return func(input interface{}) *Promise {
+			return NewPromiseAsync(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				n := atomic.AddInt32(running, 1)
+				for {
+					old := atomic.LoadInt32(peak)
+					if n <= old || atomic.CompareAndSwapInt32(peak, old, n) {
+						break
+					}
+				}
+				time.Sleep(2 * time.Millisecond)
+				atomic.AddInt32(running, -1)
+
+				if input == "fail" {
+					reject(nil, errors.New("Something went wrong"))
+				} else {
+					resolve(input.(int)*2, nil)
+				}
+			})
+		}
+	}
+
+	t.Run("nil function", func(t *testing.T) {
+		assert.Nil(t, MapLimit([]interface{}{1}, 1, nil), "Expected nil when function is nil")
+		assert.Nil(t, MapLimitSettled([]interface{}{1}, 1, nil), "Expected nil when function is nil")
+	})
+
+	t.Run("Empty inputs", func(t *testing.T) {
+		result := MapLimit(nil, 2, func(interface{}) *Promise { return nil })
+		assert.Equal(t, []interface{}{}, result.GetValue(), "Expected value to be empty")
+	})
+
+	t.Run("Ordered results with bounded concurrency", func(t *testing.T) {
+		inputs := make([]interface{}, 32)
+		expected := make([]interface{}, 32)
+		for i := range inputs {
+			inputs[i] = i
+			expected[i] = i * 2
+		}
+
+		var running, peak int32
+		value, err := MapLimit(inputs, 4, makeFactory(&running, &peak)).Await(context.Background())
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, expected, value, "Expected values in input order")
+		assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(4), "Expected at most 4 promises running at once")
+	})
+
+	t.Run("Rejects fast and stops launching", func(t *testing.T) {
+		var calls int32
+		result := MapLimit([]interface{}{1, "fail", 3, 4}, 1, func(input interface{}) *Promise {
+			atomic.AddInt32(&calls, 1)
+			return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				if input == "fail" {
+					reject(nil, errors.New("Something went wrong"))
+				} else {
+					resolve(input, nil)
+				}
+			})
+		})
+
+		assert.Equal(t, Rejected, result.getState(), "Expected state to be Rejected")
+		assert.Equal(t, "Something went wrong", result.GetReason().Error(), "Expected reason to be 'Something went wrong'")
+		assert.Equal(t, int32(2), calls, "Expected no more promises to be created after rejection")
+	})
+
+	t.Run("Settled collects all results", func(t *testing.T) {
+		var running, peak int32
+		value, err := MapLimitSettled([]interface{}{1, "fail", 3}, 2, makeFactory(&running, &peak)).Await(context.Background())
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, []SettledResult{
+			{State: Fulfilled, Value: 2, Index: 0},
+			{State: Rejected, Reason: errors.New("Something went wrong"), Index: 1},
+			{State: Fulfilled, Value: 6, Index: 2},
+		}, value, "Expected settled results in input order")
+	})
+
+	t.Run("Factory returns nil", func(t *testing.T) {
+		result := MapLimit([]interface{}{1}, 1, func(interface{}) *Promise { return nil })
+
+		assert.Equal(t, ErrNilPromise, result.GetReason(), "Expected reason to be ErrNilPromise")
+	})
+
+	t.Run("CancelUpstream cancels the promises in flight", func(t *testing.T) {
+		for _, mapLimit := range []func([]interface{}, int, func(interface{}) *Promise) *Promise{MapLimit, MapLimitSettled} {
+			var mu sync.Mutex
+			var launched []*Promise
+			result := mapLimit([]interface{}{1, 2, 3, 4}, 3, func(interface{}) *Promise {
+				p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+				mu.Lock()
+				launched = append(launched, p)
+				mu.Unlock()
+				return p
+			})
+
+			result.CancelUpstream(nil)
+
+			assert.Equal(t, Cancelled, result.getState(), "Expected state to be Cancelled")
+			mu.Lock()
+			assert.Len(t, launched, 3, "Expected no more promises to be created after cancellation")
+			for _, p := range launched {
+				assert.Equal(t, Cancelled, p.getState(), "Expected the promise in flight to be Cancelled")
+			}
+			mu.Unlock()
+		}
+	})
+}
+
+func TestSeries(t *testing.T) {
+	t.Run("Empty factories", func(t *testing.T) {
+		result := Series()
+
+		assert.Equal(t, Fulfilled, result.getState(), "Expected state to be Fulfilled")
+		assert.Nil(t, result.GetValue(), "Expected value to be nil")
+	})
+
+	t.Run("Runs factories in order", func(t *testing.T) {
+		var order []int
+		result := Series(func(prev interface{}) *Promise {
+			order = append(order, 1)
+			return NewPromiseAsync(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				resolve("Hello, World!", nil)
+			})
+		}, func(prev interface{}) *Promise {
+			order = append(order, 2)
+			return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				resolve(prev.(string)+" vowlink", nil)
+			})
+		}, func(prev interface{}) *Promise {
+			order = append(order, 3)
+			return NewPromiseAsync(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				resolve(prev.(string)+" !!", nil)
+			})
+		})
+
+		value, err := result.Await(context.Background())
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, "Hello, World! vowlink !!", value, "Expected value to be 'Hello, World! vowlink !!'")
+		assert.Equal(t, []int{1, 2, 3}, order, "Expected factories to run in order")
+	})
+
+	t.Run("Stops on rejection", func(t *testing.T) {
+		var lastCalled bool
+		result := Series(func(prev interface{}) *Promise {
+			return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				reject(nil, errors.New("Something went wrong"))
+			})
+		}, func(prev interface{}) *Promise {
+			lastCalled = true
+			return nil
+		})
+
+		assert.Equal(t, Rejected, result.getState(), "Expected state to be Rejected")
+		assert.Equal(t, "Something went wrong", result.GetReason().Error(), "Expected reason to be 'Something went wrong'")
+		assert.False(t, lastCalled, "Expected last factory not to be called")
+	})
+
+	t.Run("CancelUpstream cancels the step in flight", func(t *testing.T) {
+		var step *Promise
+		var lastCalled bool
+		result := Series(func(prev interface{}) *Promise {
+			step = NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+			return step
+		}, func(prev interface{}) *Promise {
+			lastCalled = true
+			return nil
+		})
+
+		result.CancelUpstream(nil)
+
+		assert.Equal(t, Cancelled, result.getState(), "Expected state to be Cancelled")
+		assert.Equal(t, Cancelled, step.getState(), "Expected the step in flight to be Cancelled")
+		assert.False(t, lastCalled, "Expected last factory not to be called")
+	})
+}
+
+func TestReduce(t *testing.T) {
+	sum := func(acc interface{}, value interface{}) *Promise {
+		return NewPromiseAsync(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			if value.(int) < 0 {
+				reject(nil, fmt.Errorf("negative value: %d", value))
+				return
+			}
+			resolve(acc.(int)+value.(int), nil)
+		})
+	}
+
+	t.Run("nil function", func(t *testing.T) {
+		assert.Nil(t, Reduce([]interface{}{1}, 0, nil), "Expected nil when function is nil")
+	})
+
+	t.Run("Empty inputs", func(t *testing.T) {
+		result := Reduce(nil, 10, sum)
+
+		assert.Equal(t, 10, result.GetValue(), "Expected value to be the initial value")
+	})
+
+	t.Run("Folds inputs", func(t *testing.T) {
+		value, err := Reduce([]interface{}{1, 2, 3, 4}, 10, sum).Await(context.Background())
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, 20, value, "Expected value to be 20")
+	})
+
+	t.Run("Rejected step", func(t *testing.T) {
+		_, err := Reduce([]interface{}{1, -2, 3}, 0, sum).Await(context.Background())
+
+		assert.Equal(t, "negative value: -2", err.Error(), "Expected error to be 'negative value: -2'")
+	})
+}
+
+func TestPromise_Some(t *testing.T) {
+	resolved := func(value interface{}) *Promise {
+		return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve(value, nil)
+		})
+	}
+	rejected := func(reason string) *Promise {
+		return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			reject(nil, errors.New(reason))
+		})
+	}
+
+	t.Run("Quorum reached", func(t *testing.T) {
+		result := Some(2, rejected("Promise 1 rejected"), resolved("Promise 2"), resolved("Promise 3"))
+
+		assert.Equal(t, Fulfilled, result.getState(), "Expected state to be Fulfilled")
+		assert.Equal(t, []interface{}{"Promise 2", "Promise 3"}, result.GetValue(), "Expected value to be ['Promise 2', 'Promise 3']")
+	})
+
+	t.Run("Values in completion order", func(t *testing.T) {
+		var resolveFirst func(interface{}, error)
+		p1 := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolveFirst = resolve
+		})
+
+		result := Some(2, p1, resolved("Promise 2"), resolved("Promise 3"))
+		resolveFirst("Promise 1", nil)
+
+		assert.Equal(t, []interface{}{"Promise 2", "Promise 3"}, result.GetValue(), "Expected value to be ['Promise 2', 'Promise 3']")
+	})
+
+	t.Run("Quorum impossible", func(t *testing.T) {
+		p3 := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+
+		result := Some(2, rejected("Promise 1 rejected"), rejected("Promise 2 rejected"), p3)
+
+		assert.Equal(t, Rejected, result.getState(), "Expected state to be Rejected before all inputs settle")
+		assert.Equal(t, &AggregateError{Errors: []error{errors.New("Promise 1 rejected"), errors.New("Promise 2 rejected"), nil}}, result.GetReason(), "Expected reason to be an AggregateError indexed by input")
+	})
+
+	t.Run("n larger than inputs", func(t *testing.T) {
+		result := Some(3, resolved("Promise 1"))
+
+		assert.Equal(t, Rejected, result.getState(), "Expected state to be Rejected")
+		assert.ErrorIs(t, result.GetReason(), ErrNotEnoughPromises, "Expected reason to be ErrNotEnoughPromises")
+		var aggregateErr *AggregateError
+		assert.False(t, errors.As(result.GetReason(), &aggregateErr), "Expected reason not to look like rejected inputs")
+		assert.Equal(t, "not enough promises to reach quorum: need 3, got 1", result.GetReason().Error(), "Expected message to include the counts")
+	})
+
+	t.Run("n is zero", func(t *testing.T) {
+		result := Some(0, rejected("Promise 1 rejected"))
+
+		assert.Equal(t, Fulfilled, result.getState(), "Expected state to be Fulfilled")
+		assert.Equal(t, []interface{}{}, result.GetValue(), "Expected value to be empty")
+	})
+}
+
+func TestAllMap(t *testing.T) {
+	t.Run("All promises fulfilled", func(t *testing.T) {
+		result := AllMap(map[string]*Promise{
+			"user": NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				resolve("alice", nil)
+			}),
+			"orders": NewPromiseAsync(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				resolve(3, nil)
+			}),
+		})
+
+		value, err := result.Await(context.Background())
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, map[string]interface{}{"user": "alice", "orders": 3}, value, "Expected values to be keyed by input")
+	})
+
+	t.Run("One promise rejected", func(t *testing.T) {
+		result := AllMap(map[string]*Promise{
+			"user": NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				resolve("alice", nil)
+			}),
+			"orders": NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				reject(nil, errors.New("orders unavailable"))
+			}),
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, Rejected, result.getState(), "Expected state to be Rejected")
+		assert.Equal(t, "orders unavailable", result.GetReason().Error(), "Expected reason to be 'orders unavailable'")
+	})
+
+	t.Run("Empty map", func(t *testing.T) {
+		result := AllMap(nil)
+
+		waitSettled(t, result)
+		assert.Equal(t, map[string]interface{}{}, result.GetValue(), "Expected value to be empty")
+	})
+}
+
+func TestAllSettledMap(t *testing.T) {
+	result := AllSettledMap(map[string]*Promise{
+		"user": NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("alice", nil)
+		}),
+		"orders": NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			reject(nil, errors.New("orders unavailable"))
+		}),
+	})
+
+	waitSettled(t, result)
+	assert.Equal(t, map[string]SettledResult{
+		"user":   {State: Fulfilled, Value: "alice", Index: -1},
+		"orders": {State: Rejected, Reason: errors.New("orders unavailable"), Index: -1},
+	}, result.GetValue(), "Expected results to be keyed by input")
+}
+
+type testCodeError struct {
+	code int
+}
+
+func (e *testCodeError) Error() string {
+	return fmt.Sprintf("code %d", e.code)
+}
+
+func TestAggregateError(t *testing.T) {
+	errNotFound := errors.New("not found")
+
+	t.Run("Error skips nil entries", func(t *testing.T) {
+		err := &AggregateError{Errors: []error{nil, errNotFound, nil}}
+
+		assert.Equal(t, "All promises were rejected: not found", err.Error(), "Expected message to skip nil entries")
+		assert.Equal(t, "All promises were rejected", (&AggregateError{Errors: []error{nil}}).Error(), "Expected message without errors")
+	})
+
+	t.Run("Unwrap and IndexedErrors skip nil entries", func(t *testing.T) {
+		codeErr := &testCodeError{code: 404}
+		err := &AggregateError{Errors: []error{nil, errNotFound, nil, codeErr}}
+
+		assert.Equal(t, []error{errNotFound, codeErr}, err.Unwrap(), "Expected non-nil errors")
+		assert.Equal(t, []IndexedError{{Index: 1, Err: errNotFound}, {Index: 3, Err: codeErr}}, err.IndexedErrors(), "Expected errors with their positions")
+	})
+
+	t.Run("errors.Is and errors.As", func(t *testing.T) {
+		var err error = &AggregateError{Errors: []error{fmt.Errorf("wrapped: %w", errNotFound), &testCodeError{code: 500}}}
+
+		assert.True(t, errors.Is(err, errNotFound), "Expected errors.Is to find the wrapped error")
+		assert.False(t, errors.Is(err, ErrTimeout), "Expected errors.Is not to find an unrelated error")
+
+		var codeErr *testCodeError
+		assert.True(t, errors.As(err, &codeErr), "Expected errors.As to find the typed error")
+		assert.Equal(t, 500, codeErr.code, "Expected code to be 500")
+	})
+
+	t.Run("JoinErrors", func(t *testing.T) {
+		assert.Nil(t, JoinErrors(), "Expected nil without errors")
+		assert.Nil(t, JoinErrors(nil, nil), "Expected nil when all errors are nil")
+
+		err := JoinErrors(nil, errNotFound)
+		assert.Equal(t, &AggregateError{Errors: []error{nil, errNotFound}}, err, "Expected positions to be preserved")
+		assert.True(t, errors.Is(err, errNotFound), "Expected errors.Is to find the joined error")
+	})
+
+	t.Run("Any rejection is indexed by input", func(t *testing.T) {
+		var rejectFirst func(interface{}, error)
+		p1 := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			rejectFirst = reject
+		})
+		p2 := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			reject(nil, errNotFound)
+		})
+
+		result := Any(p1, p2)
+		rejectFirst(nil, ErrTimeout)
+
+		err := result.GetReason()
+		assert.Equal(t, []IndexedError{{Index: 0, Err: ErrTimeout}, {Index: 1, Err: errNotFound}}, err.(*AggregateError).IndexedErrors(), "Expected errors in input order")
+		assert.True(t, errors.Is(err, ErrTimeout), "Expected errors.Is to find ErrTimeout")
+	})
+}
+
+func TestPromise_ChainError(t *testing.T) {
+	t.Run("Disabled by default", func(t *testing.T) {
+		result := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			reject(nil, errors.New("Something went wrong"))
+		}).Then(nil, nil)
+
+		waitSettled(t, result)
+		assert.Equal(t, errors.New("Something went wrong"), result.GetReason(), "Expected reason not to be wrapped")
+	})
+
+	t.Run("Records the stage that produced the rejection", func(t *testing.T) {
+		SetChainErrors(true)
+		defer SetChainErrors(false)
+
+		errParse := errors.New("invalid input")
+		result := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("Hello, World!", nil)
+		}).ThenNamed("load", func(value interface{}) (interface{}, error) {
+			return value, nil
+		}, nil).ThenNamed("parse", func(value interface{}) (interface{}, error) {
+			return nil, errParse
+		}, nil).ThenNamed("render", func(value interface{}) (interface{}, error) {
+			return value, nil
+		}, nil).Finally(nil)
+
+		var chainErr *ChainError
+		waitSettled(t, result)
+		assert.True(t, errors.As(result.GetReason(), &chainErr), "Expected reason to be a ChainError")
+		assert.Equal(t, 2, chainErr.Stage, "Expected stage to be 2")
+		assert.Equal(t, "parse", chainErr.Label, "Expected label to be 'parse'")
+		assert.Equal(t, errParse, chainErr.Cause, "Expected cause to be the original error")
+		assert.True(t, errors.Is(result.GetReason(), errParse), "Expected errors.Is to find the original error")
+		assert.Equal(t, "stage 2 (parse): invalid input", result.GetReason().Error(), "Expected message to include the stage")
+	})
+
+	t.Run("Rejection from the promise handler", func(t *testing.T) {
+		SetChainErrors(true)
+		defer SetChainErrors(false)
+
+		result := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			reject(nil, errors.New("Something went wrong"))
+		}).Then(func(value interface{}) (interface{}, error) {
+			return value, nil
+		}, nil).Catch(nil)
+
+		waitSettled(t, result)
+		assert.Equal(t, &ChainError{Stage: 0, Cause: errors.New("Something went wrong")}, result.GetReason(), "Expected stage to be 0")
+		assert.Equal(t, "stage 0: Something went wrong", result.GetReason().Error(), "Expected message to include the stage")
+	})
+
+	t.Run("Handler returning a new error starts a new stage", func(t *testing.T) {
+		SetChainErrors(true)
+		defer SetChainErrors(false)
+
+		var rejectLater func(interface{}, error)
+		result := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			rejectLater = reject
+		}).Catch(func(reason error) (interface{}, error) {
+			return nil, fmt.Errorf("handled: %w", reason)
+		}).Label("recover")
+
+		rejectLater(nil, errors.New("Something went wrong"))
+
+		waitSettled(t, result)
+		chainErr := result.GetReason().(*ChainError)
+		assert.Equal(t, 1, chainErr.Stage, "Expected stage to be 1")
+		assert.Equal(t, "recover", chainErr.Label, "Expected label to be 'recover'")
+		assert.Equal(t, "stage 1 (recover): handled: stage 0: Something went wrong", chainErr.Error(), "Expected message to include both stages")
+	})
+}
+
+// testThenable 是一个用于测试的自定义 Thenable，保存一次性的结果
+type testThenable struct {
+	value  interface{}
+	reason error
+}
+
+func (tt *testThenable) Then(onFulfilled func(interface{}), onRejected func(error)) {
+	if tt.reason != nil {
+		onRejected(tt.reason)
+	} else {
+		onFulfilled(tt.value)
+	}
+}
+
+func TestPromise_Assimilation(t *testing.T) {
+	t.Run("Resolve with a pending Promise", func(t *testing.T) {
+		var resolveInner func(interface{}, error)
+		inner := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolveInner = resolve
+		})
+
+		result := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("Hello, World!", nil)
+		}).Then(func(value interface{}) (interface{}, error) {
+			return inner, nil
+		}, nil)
+
+		assert.Equal(t, Pending, result.getState(), "Expected state to be Pending")
+
+		resolveInner("vowlink", nil)
+
+		waitSettled(t, result)
+		assert.Equal(t, Fulfilled, result.getState(), "Expected state to be Fulfilled")
+		assert.Equal(t, "vowlink", result.GetValue(), "Expected value to be 'vowlink'")
+	})
+
+	t.Run("Resolve and reject are ignored after following", func(t *testing.T) {
+		var resolveInner func(interface{}, error)
+		inner := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolveInner = resolve
+		})
+
+		result := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve(inner, nil)
+			resolve("ignored", nil)
+			reject(nil, errors.New("ignored"))
+		})
+
+		assert.Equal(t, Pending, result.getState(), "Expected state to be Pending")
+
+		resolveInner("vowlink", nil)
+
+		assert.Equal(t, Fulfilled, result.getState(), "Expected state to be Fulfilled")
+		assert.Equal(t, "vowlink", result.GetValue(), "Expected value to be 'vowlink'")
+	})
+
+	t.Run("Catch returning a Promise", func(t *testing.T) {
+		result := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			reject(nil, errors.New("Something went wrong"))
+		}).Catch(func(reason error) (interface{}, error) {
+			return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				resolve("recovered", nil)
+			}), nil
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, Fulfilled, result.getState(), "Expected state to be Fulfilled")
+		assert.Equal(t, "recovered", result.GetValue(), "Expected value to be 'recovered'")
+	})
+
+	t.Run("Cancellation of the followed Promise", func(t *testing.T) {
+		inner := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+		result := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve(inner, nil)
+		})
+
+		inner.Cancel(nil)
+
+		assert.Equal(t, Cancelled, result.getState(), "Expected state to be Cancelled")
+		assert.Equal(t, ErrCancelled, result.GetReason(), "Expected reason to be ErrCancelled")
+	})
+
+	t.Run("Custom Thenable", func(t *testing.T) {
+		fulfilled := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve(&testThenable{value: &testThenable{value: "nested"}}, nil)
+		})
+		rejected := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve(&testThenable{reason: errors.New("Something went wrong")}, nil)
+		})
+
+		assert.Equal(t, Fulfilled, fulfilled.getState(), "Expected state to be Fulfilled")
+		assert.Equal(t, "nested", fulfilled.GetValue(), "Expected value to be 'nested'")
+		assert.Equal(t, Rejected, rejected.getState(), "Expected state to be Rejected")
+		assert.Equal(t, "Something went wrong", rejected.GetReason().Error(), "Expected reason to be 'Something went wrong'")
+	})
+
+	t.Run("Resolve with itself", func(t *testing.T) {
+		var resolveLater func(interface{}, error)
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolveLater = resolve
+		})
+
+		resolveLater(p, nil)
+
+		assert.Equal(t, Rejected, p.getState(), "Expected state to be Rejected")
+		assert.Equal(t, ErrPromiseCycle, p.GetReason(), "Expected reason to be ErrPromiseCycle")
+	})
+
+	t.Run("Resolve with a nil Promise", func(t *testing.T) {
+		var inner *Promise
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve(inner, nil)
+		})
+
+		assert.Equal(t, Fulfilled, p.getState(), "Expected state to be Fulfilled")
+		assert.Nil(t, p.GetValue(), "Expected value to be a nil Promise")
+	})
+}
+
+func TestPromise_State(t *testing.T) {
+	t.Run("State matches the path taken", func(t *testing.T) {
+		var resolveLater func(interface{}, error)
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolveLater = resolve
+		})
+		assert.Equal(t, Pending, p.State(), "Expected state to be Pending")
+
+		resolveLater(nil, errors.New("Something went wrong"))
+
+		assert.Equal(t, Rejected, p.State(), "Expected state to be Rejected")
+		assert.Equal(t, "rejected", p.State().String(), "Expected state name to be 'rejected'")
+	})
+
+	t.Run("Reject without reason in lenient mode", func(t *testing.T) {
+		var path string
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			reject("Hello, World!", nil)
+		})
+		result := p.Then(func(value interface{}) (interface{}, error) {
+			path = "success"
+			return value, nil
+		}, func(reason error) (interface{}, error) {
+			path = "error"
+			return nil, reason
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, Fulfilled, p.State(), "Expected state to be Fulfilled")
+		assert.Equal(t, "Hello, World!", p.GetValue(), "Expected value to be 'Hello, World!'")
+		assert.Equal(t, "success", path, "Expected the success path to be taken")
+	})
+
+	t.Run("Reject without reason in strict mode", func(t *testing.T) {
+		SetStrictMode(true)
+		defer SetStrictMode(false)
+
+		var path string
+		p := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			reject("Hello, World!", nil)
+		})
+		result := p.Then(func(value interface{}) (interface{}, error) {
+			path = "success"
+			return value, nil
+		}, func(reason error) (interface{}, error) {
+			path = "error"
+			return nil, reason
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, Rejected, p.State(), "Expected state to be Rejected")
+		assert.Equal(t, ErrRejectedWithoutReason, p.GetReason(), "Expected reason to be ErrRejectedWithoutReason")
+		assert.Equal(t, "error", path, "Expected the error path to be taken")
+	})
+
+	t.Run("Unknown state name", func(t *testing.T) {
+		assert.Equal(t, "PromiseState(9)", PromiseState(9).String(), "Expected the numeric value for an unknown state")
+	})
+}
+
+func TestPromise_AssimilationCancellation(t *testing.T) {
+	t.Run("Context cancellation reaches a follower", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		inner := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+
+		tail := NewPromiseWithContext(ctx, func(ctx context.Context, resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve(1, nil)
+		}).Then(func(value interface{}) (interface{}, error) {
+			return inner, nil
+		}, nil).Then(func(value interface{}) (interface{}, error) {
+			return value, nil
+		}, nil)
+
+		cancel()
+
+		waitCtx, waitCancel := context.WithTimeout(context.Background(), time.Second)
+		defer waitCancel()
+		_, err := tail.Await(waitCtx)
+		assert.Equal(t, context.Canceled, err, "Expected the tail to be rejected with context.Canceled")
+		assert.Equal(t, Pending, inner.getState(), "Expected the followed promise to be left alone")
+	})
+
+	t.Run("CancelUpstream reaches the followed promise", func(t *testing.T) {
+		inner := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+		outer := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve(inner, nil)
+		})
+
+		outer.CancelUpstream(nil)
+
+		assert.Equal(t, Cancelled, outer.getState(), "Expected outer to be Cancelled")
+		assert.Equal(t, Cancelled, inner.getState(), "Expected the followed promise to be Cancelled")
+	})
+
+	t.Run("CancelUpstream with promises following each other", func(t *testing.T) {
+		var resolveA, resolveB func(interface{}, error)
+		a := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolveA = resolve
+		})
+		b := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolveB = resolve
+		})
+		resolveA(b, nil)
+		resolveB(a, nil)
+
+		a.CancelUpstream(nil)
+
+		assert.Equal(t, Cancelled, a.getState(), "Expected a to be Cancelled")
+		assert.Equal(t, Cancelled, b.getState(), "Expected b to be Cancelled")
+	})
+}
+
+// 等待 Promise 结束，超时时测试失败
+func waitSettled(t *testing.T, p *Promise) {
+	t.Helper()
+
+	select {
+	case <-p.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected the Promise to settle")
+	}
 }
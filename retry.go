@@ -0,0 +1,162 @@
+package vowlink
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff 返回第 attempt 次尝试失败后、下一次尝试前的等待时间
+// previous 为上一次返回的等待时间，第一次调用时为 0
+type Backoff func(attempt int, previous time.Duration) time.Duration
+
+// ConstantBackoff 每次重试前等待固定的时间
+func ConstantBackoff(delay time.Duration) Backoff {
+	return func(int, time.Duration) time.Duration {
+		return delay
+	}
+}
+
+// ExponentialBackoff 每次重试前的等待时间从 base 开始按 2 的幂增长，最大不超过 max
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return func(attempt int, _ time.Duration) time.Duration {
+		delay := base
+		for i := 1; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+		if delay > max {
+			delay = max
+		}
+		return delay
+	}
+}
+
+// DecorrelatedJitterBackoff 每次重试前的等待时间在 [base, previous*3) 之间随机选取，最大不超过 max
+// 参考 AWS 架构博客中的 "Decorrelated Jitter" 算法
+func DecorrelatedJitterBackoff(base, max time.Duration) Backoff {
+	return func(_ int, previous time.Duration) time.Duration {
+		if previous < base {
+			previous = base
+		}
+
+		delay := base
+		if upper := previous * 3; upper > base {
+			delay += time.Duration(rand.Int63n(int64(upper - base)))
+		}
+		if delay > max {
+			delay = max
+		}
+		return delay
+	}
+}
+
+// RetryOption 配置 Retry 的行为
+type RetryOption func(*retryConfig)
+
+type retryConfig struct {
+	maxAttempts int
+	backoff     Backoff
+	retryIf     func(error) bool
+	onRetry     func(attempt int, reason error)
+}
+
+// 默认最多尝试 3 次，重试之间不等待，所有错误都会重试
+func newRetryConfig() *retryConfig {
+	return &retryConfig{
+		maxAttempts: 3,
+		backoff:     ConstantBackoff(0),
+		retryIf:     func(error) bool { return true },
+	}
+}
+
+// WithMaxAttempts 设置最大尝试次数（包括第一次），小于 1 时按 1 处理
+func WithMaxAttempts(attempts int) RetryOption {
+	return func(c *retryConfig) {
+		if attempts < 1 {
+			attempts = 1
+		}
+		c.maxAttempts = attempts
+	}
+}
+
+// WithBackoff 设置重试之间的等待策略
+func WithBackoff(backoff Backoff) RetryOption {
+	return func(c *retryConfig) {
+		if backoff != nil {
+			c.backoff = backoff
+		}
+	}
+}
+
+// WithRetryIf 设置判断错误是否可以重试的函数，返回 false 时立即停止重试
+func WithRetryIf(retryIf func(error) bool) RetryOption {
+	return func(c *retryConfig) {
+		if retryIf != nil {
+			c.retryIf = retryIf
+		}
+	}
+}
+
+// WithOnRetry 设置每次尝试失败且即将重试时调用的函数，attempt 为失败的尝试序号（从 1 开始）
+func WithOnRetry(onRetry func(attempt int, reason error)) RetryOption {
+	return func(c *retryConfig) {
+		c.onRetry = onRetry
+	}
+}
+
+// Retry 调用 factory 创建 Promise，并在其被拒绝时按照配置重新调用 factory
+// 任意一次尝试成功时，结果 Promise 以该值完成
+// 所有尝试都失败、错误不可重试或尝试被取消时，结果 Promise 以包含每次尝试错误的 AggregateError 被拒绝
+func Retry(factory func() *Promise, opts ...RetryOption) *Promise {
+	if factory == nil {
+		return nil
+	}
+
+	config := newRetryConfig()
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	result := &Promise{state: Pending}
+	errors := NewAggregateError(config.maxAttempts)
+
+	var attempt func(n int, previous time.Duration)
+	attempt = func(n int, previous time.Duration) {
+		// 结果 Promise 已被取消，不再继续尝试
+		if result.getState() != Pending {
+			return
+		}
+
+		p := callFactory(factory)
+		result.addUpstream(p)
+		p.subscribe(func() {
+			result.run(func() {
+				state, value, reason := p.snapshot()
+				if reason == nil {
+					result.resolve(value, nil)
+					return
+				}
+
+				errors.Errors = append(errors.Errors, reason)
+				if state == Cancelled || n >= config.maxAttempts || !config.retryIf(reason) {
+					result.reject(nil, errors)
+					return
+				}
+
+				if config.onRetry != nil {
+					config.onRetry(n, reason)
+				}
+
+				delay := config.backoff(n, previous)
+				if delay <= 0 {
+					attempt(n+1, delay)
+				} else {
+					time.AfterFunc(delay, func() { attempt(n+1, delay) })
+				}
+			})
+		})
+	}
+
+	attempt(1, 0)
+
+	return result
+}
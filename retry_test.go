@@ -0,0 +1,161 @@
+package vowlink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 创建一个前 failures 次调用被拒绝、之后以调用次数完成的 Promise 工厂函数
+func makeFlakyFactory(failures int32, calls *int32) func() *Promise {
+	return func() *Promise {
+		n := atomic.AddInt32(calls, 1)
+		return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			if n <= failures {
+				reject(nil, fmt.Errorf("attempt %d failed", n))
+			} else {
+				resolve(n, nil)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	t.Run("nil factory", func(t *testing.T) {
+		assert.Nil(t, Retry(nil), "Expected nil when factory is nil")
+	})
+
+	t.Run("Succeeds after failures", func(t *testing.T) {
+		var calls int32
+		result := Retry(makeFlakyFactory(2, &calls))
+
+		assert.Equal(t, Fulfilled, result.getState(), "Expected state to be Fulfilled")
+		assert.Equal(t, int32(3), result.GetValue(), "Expected value to be 3")
+		assert.Equal(t, int32(3), calls, "Expected factory to be called 3 times")
+	})
+
+	t.Run("All attempts fail", func(t *testing.T) {
+		var calls int32
+		result := Retry(makeFlakyFactory(10, &calls), WithMaxAttempts(4))
+
+		assert.Equal(t, Rejected, result.getState(), "Expected state to be Rejected")
+		assert.Equal(t, int32(4), calls, "Expected factory to be called 4 times")
+		assert.Equal(t, &AggregateError{Errors: []error{
+			errors.New("attempt 1 failed"),
+			errors.New("attempt 2 failed"),
+			errors.New("attempt 3 failed"),
+			errors.New("attempt 4 failed"),
+		}}, result.GetReason(), "Expected reason to be an AggregateError of all attempts")
+	})
+
+	t.Run("Non-retryable error stops retrying", func(t *testing.T) {
+		var calls int32
+		result := Retry(makeFlakyFactory(10, &calls), WithRetryIf(func(err error) bool {
+			return err.Error() != "attempt 2 failed"
+		}))
+
+		assert.Equal(t, int32(2), calls, "Expected factory to be called 2 times")
+		assert.Len(t, result.GetReason().(*AggregateError).Errors, 2, "Expected 2 errors to be collected")
+	})
+
+	t.Run("OnRetry hook", func(t *testing.T) {
+		var calls int32
+		var attempts []int
+		Retry(makeFlakyFactory(2, &calls), WithOnRetry(func(attempt int, reason error) {
+			attempts = append(attempts, attempt)
+		}))
+
+		assert.Equal(t, []int{1, 2}, attempts, "Expected hook to be called before each retry")
+	})
+
+	t.Run("Backoff between attempts", func(t *testing.T) {
+		var calls int32
+		start := time.Now()
+		result := Retry(makeFlakyFactory(2, &calls), WithBackoff(ConstantBackoff(10*time.Millisecond)))
+
+		assert.Equal(t, Pending, result.getState(), "Expected state to be Pending while waiting")
+
+		value, err := result.Await(context.Background())
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, int32(3), value, "Expected value to be 3")
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond, "Expected to wait between attempts")
+	})
+
+	t.Run("Factory returns nil or panics", func(t *testing.T) {
+		var calls int32
+		result := Retry(func() *Promise {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return nil
+			}
+			panic("Something went wrong")
+		}, WithMaxAttempts(2))
+
+		errs := result.GetReason().(*AggregateError).Errors
+		assert.Equal(t, ErrNilPromise, errs[0], "Expected first error to be ErrNilPromise")
+		assert.IsType(t, &PanicError{}, errs[1], "Expected second error to be a PanicError")
+	})
+
+	t.Run("Cancelled result stops retrying", func(t *testing.T) {
+		var calls int32
+		result := Retry(makeFlakyFactory(10, &calls), WithMaxAttempts(10), WithBackoff(ConstantBackoff(20*time.Millisecond)))
+
+		result.Cancel(nil)
+		time.Sleep(50 * time.Millisecond)
+
+		assert.Equal(t, Cancelled, result.getState(), "Expected state to be Cancelled")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Expected no more attempts after cancellation")
+	})
+
+	t.Run("CancelUpstream cancels the attempt in flight", func(t *testing.T) {
+		var attempt *Promise
+		result := Retry(func() *Promise {
+			attempt = NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {})
+			return attempt
+		})
+
+		result.CancelUpstream(nil)
+
+		assert.Equal(t, Cancelled, result.getState(), "Expected state to be Cancelled")
+		assert.Equal(t, Cancelled, attempt.getState(), "Expected the attempt to be Cancelled")
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	t.Run("Constant", func(t *testing.T) {
+		backoff := ConstantBackoff(time.Second)
+
+		assert.Equal(t, time.Second, backoff(1, 0))
+		assert.Equal(t, time.Second, backoff(5, time.Second))
+	})
+
+	t.Run("Exponential", func(t *testing.T) {
+		backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+
+		assert.Equal(t, 10*time.Millisecond, backoff(1, 0))
+		assert.Equal(t, 20*time.Millisecond, backoff(2, 0))
+		assert.Equal(t, 40*time.Millisecond, backoff(3, 0))
+		assert.Equal(t, 50*time.Millisecond, backoff(4, 0))
+		assert.Equal(t, 50*time.Millisecond, backoff(100, 0))
+	})
+
+	t.Run("Decorrelated jitter", func(t *testing.T) {
+		base, max := 10*time.Millisecond, time.Second
+		backoff := DecorrelatedJitterBackoff(base, max)
+
+		previous := time.Duration(0)
+		for attempt := 1; attempt <= 100; attempt++ {
+			delay := backoff(attempt, previous)
+			assert.GreaterOrEqual(t, delay, base, "Expected delay to be at least base")
+			assert.LessOrEqual(t, delay, max, "Expected delay to be at most max")
+			if previous > 0 {
+				assert.Less(t, delay, previous*3+1, "Expected delay to be less than previous*3")
+			}
+			previous = delay
+		}
+	})
+}
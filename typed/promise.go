@@ -0,0 +1,396 @@
+package typed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	vl "github.com/shengyanli1982/vowlink"
+)
+
+var (
+	ErrNilPromise   = errors.New("typed: nil promise returned") // FlatMap 的转换函数返回了 nil Promise
+	ErrTypeMismatch = errors.New("typed: value type mismatch")  // 底层 Promise 的值不是期望的类型
+)
+
+// Promise 表示一个带有类型参数的异步操作，底层由 vowlink.Promise 驱动
+type Promise[T any] struct {
+	p *vl.Promise
+}
+
+// 将 interface{} 值转换为类型 T，类型不匹配时返回零值和 false
+// 只有 T 的零值本身为 nil（指针、接口、切片、映射、通道和函数）时，nil 才被视为匹配
+func cast[T any](value interface{}) (T, bool) {
+	if value == nil {
+		var zero T
+		switch reflect.TypeOf((*T)(nil)).Elem().Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map, reflect.Chan, reflect.Func:
+			return zero, true
+		default:
+			return zero, false
+		}
+	}
+
+	v, ok := value.(T)
+	return v, ok
+}
+
+// 将 interface{} 值转换为类型 T，类型不匹配时返回包装了 ErrTypeMismatch 的错误
+func convert[T any](value interface{}) (T, error) {
+	v, ok := cast[T](value)
+	if !ok {
+		return v, fmt.Errorf("%w: expected %s, got %T", ErrTypeMismatch, reflect.TypeOf((*T)(nil)).Elem(), value)
+	}
+	return v, nil
+}
+
+// 将 []interface{} 转换为 []T，任意一个元素类型不匹配时返回错误
+func convertSlice[T any](values []interface{}) ([]T, error) {
+	result := make([]T, len(values))
+	for i, value := range values {
+		v, err := convert[T](value)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = v
+	}
+	return result, nil
+}
+
+// 作为 Then 的成功回调检查值的类型，类型不匹配时派生的 Promise 被拒绝
+func checked[T any](value interface{}) (interface{}, error) {
+	return convert[T](value)
+}
+
+// 将 vowlink 的 reject 适配为 Promise[T] 使用的 reject
+// Promise[T] 的 reject 总是拒绝 Promise，reason 为 nil 时以 vowlink.ErrRejectedWithoutReason 拒绝
+func rejecter(reject func(interface{}, error)) func(error) {
+	return func(reason error) {
+		if reason == nil {
+			reason = vl.ErrRejectedWithoutReason
+		}
+		reject(nil, reason)
+	}
+}
+
+// From 将一个 vowlink.Promise 包装为 Promise[T]
+func From[T any](p *vl.Promise) *Promise[T] {
+	if p == nil {
+		return nil
+	}
+	return &Promise[T]{p: p}
+}
+
+// NewPromise 使用给定的处理函数创建新的 Promise[T]
+func NewPromise[T any](promiseHandler func(resolve func(T), reject func(error))) *Promise[T] {
+	if promiseHandler == nil {
+		return nil
+	}
+
+	return From[T](vl.NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+		promiseHandler(func(value T) { resolve(value, nil) }, rejecter(reject))
+	}))
+}
+
+// NewPromiseAsync 与 NewPromise 类似，但处理函数在新的 goroutine 中执行
+func NewPromiseAsync[T any](promiseHandler func(resolve func(T), reject func(error))) *Promise[T] {
+	if promiseHandler == nil {
+		return nil
+	}
+
+	return From[T](vl.NewPromiseAsync(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+		promiseHandler(func(value T) { resolve(value, nil) }, rejecter(reject))
+	}))
+}
+
+// NewPromiseWithContext 使用给定的上下文和处理函数创建新的 Promise[T]
+// 如果 ctx 在 Promise 结束前被取消，Promise 会以 ctx.Err() 被拒绝
+func NewPromiseWithContext[T any](ctx context.Context, promiseHandler func(ctx context.Context, resolve func(T), reject func(error))) *Promise[T] {
+	if promiseHandler == nil {
+		return nil
+	}
+
+	return From[T](vl.NewPromiseWithContext(ctx, func(ctx context.Context, resolve func(interface{}, error), reject func(interface{}, error)) {
+		promiseHandler(ctx, func(value T) { resolve(value, nil) }, rejecter(reject))
+	}))
+}
+
+// Resolve 返回一个以 value 完成的 Promise[T]
+func Resolve[T any](value T) *Promise[T] {
+	return NewPromise(func(resolve func(T), reject func(error)) {
+		resolve(value)
+	})
+}
+
+// Reject 返回一个以 reason 拒绝的 Promise[T]
+func Reject[T any](reason error) *Promise[T] {
+	return NewPromise(func(resolve func(T), reject func(error)) {
+		reject(reason)
+	})
+}
+
+// Untyped 返回底层的 vowlink.Promise
+func (p *Promise[T]) Untyped() *vl.Promise {
+	return p.p
+}
+
+// Then 注册 Promise 完成时要调用的回调函数
+func (p *Promise[T]) Then(successHandler func(T) (T, error), errorHandler func(error) (T, error)) *Promise[T] {
+	var onFulfilled func(interface{}) (interface{}, error)
+	if successHandler != nil {
+		onFulfilled = func(value interface{}) (interface{}, error) {
+			v, err := convert[T](value)
+			if err != nil {
+				return nil, err
+			}
+			return successHandler(v)
+		}
+	}
+
+	var onRejected func(error) (interface{}, error)
+	if errorHandler != nil {
+		onRejected = func(reason error) (interface{}, error) {
+			return errorHandler(reason)
+		}
+	}
+
+	return From[T](p.p.Then(onFulfilled, onRejected))
+}
+
+// Catch 注册 Promise 被拒绝时要调用的回调函数
+func (p *Promise[T]) Catch(errorHandler func(error) (T, error)) *Promise[T] {
+	return p.Then(nil, errorHandler)
+}
+
+// Finally 注册无论 Promise 状态如何都会调用的清理回调函数
+func (p *Promise[T]) Finally(cleanupHandler func() error) *Promise[T] {
+	return From[T](p.p.Finally(cleanupHandler))
+}
+
+// WithTimeout 返回一个与当前 Promise 结果相同的 Promise，超时未结束时以 vowlink.ErrTimeout 被拒绝
+func (p *Promise[T]) WithTimeout(d time.Duration) *Promise[T] {
+	return From[T](vl.Timeout(p.p, d))
+}
+
+// Cancel 取消处于 Pending 状态的 Promise，并将取消传播到派生的 Promise
+func (p *Promise[T]) Cancel(reason error) {
+	p.p.Cancel(reason)
+}
+
+// CancelUpstream 与 Cancel 类似，但还会向上取消上游的 Promise 和组合函数的输入
+func (p *Promise[T]) CancelUpstream(reason error) {
+	p.p.CancelUpstream(reason)
+}
+
+// Await 阻塞等待 Promise 结束或 ctx 被取消
+// 完成的值不是类型 T 时返回包装了 ErrTypeMismatch 的错误
+func (p *Promise[T]) Await(ctx context.Context) (T, error) {
+	value, err := p.p.Await(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return convert[T](value)
+}
+
+// Done 返回一个在 Promise 结束时被关闭的通道
+func (p *Promise[T]) Done() <-chan struct{} {
+	return p.p.Done()
+}
+
+// State 返回 Promise 当前的状态
+func (p *Promise[T]) State() vl.PromiseState {
+	return p.p.State()
+}
+
+// GetValue 返回 Promise 当前的值，值不是类型 T 时返回零值，需要区分时使用 Await
+func (p *Promise[T]) GetValue() T {
+	v, _ := cast[T](p.p.GetValue())
+	return v
+}
+
+func (p *Promise[T]) GetReason() error {
+	return p.p.GetReason()
+}
+
+// Map 使用 fn 将 Promise[T] 的值转换为类型 U
+func Map[T, U any](p *Promise[T], fn func(T) (U, error)) *Promise[U] {
+	return From[U](p.p.Then(func(value interface{}) (interface{}, error) {
+		v, err := convert[T](value)
+		if err != nil {
+			return nil, err
+		}
+		return fn(v)
+	}, nil))
+}
+
+// FlatMap 使用 fn 将 Promise[T] 的值转换为新的 Promise[U]，并采用其最终状态
+// fn 发生 panic 或返回 nil 时，结果 Promise 被拒绝；上下文和 CancelUpstream 沿链条正常传递
+func FlatMap[T, U any](p *Promise[T], fn func(T) *Promise[U]) *Promise[U] {
+	return From[U](p.p.Then(func(value interface{}) (interface{}, error) {
+		v, err := convert[T](value)
+		if err != nil {
+			return nil, err
+		}
+		next := fn(v)
+		if next == nil {
+			return nil, ErrNilPromise
+		}
+		return next.p, nil
+	}, nil))
+}
+
+// Settled 表示 AllSettled 中单个 Promise 的结束结果
+type Settled[T any] struct {
+	State  vl.PromiseState
+	Value  T
+	Reason error
+}
+
+// 返回 Promise[T] 切片对应的底层 vowlink.Promise 切片
+func untyped[T any](promises []*Promise[T]) []*vl.Promise {
+	result := make([]*vl.Promise, len(promises))
+	for i, promise := range promises {
+		result[i] = promise.p
+	}
+	return result
+}
+
+// All 等待所有 Promise 完成，按输入顺序返回所有值
+// 如果任何一个 Promise 被拒绝，结果 Promise 也会被拒绝
+func All[T any](promises ...*Promise[T]) *Promise[[]T] {
+	return From[[]T](vl.All(untyped(promises)...).Then(func(value interface{}) (interface{}, error) {
+		return convertSlice[T](value.([]interface{}))
+	}, nil))
+}
+
+// AllSettled 等待所有 Promise 完成，无论其状态如何，按输入顺序返回每个 Promise 的结果
+func AllSettled[T any](promises ...*Promise[T]) *Promise[[]Settled[T]] {
+	return From[[]Settled[T]](vl.AllSettled(untyped(promises)...).Then(func(value interface{}) (interface{}, error) {
+		return settledResults[T](value.([]vl.SettledResult)), nil
+	}, nil))
+}
+
+// 将 []vowlink.SettledResult 转换为 []Settled[T]
+// 完成的值不是类型 T 时，对应的结果被视为以类型错误被拒绝
+func settledResults[T any](results []vl.SettledResult) []Settled[T] {
+	settled := make([]Settled[T], len(results))
+	for i, r := range results {
+		settled[i] = Settled[T]{State: r.State, Reason: r.Reason}
+		if r.State != vl.Fulfilled {
+			continue
+		}
+		v, err := convert[T](r.Value)
+		if err != nil {
+			settled[i] = Settled[T]{State: vl.Rejected, Reason: err}
+			continue
+		}
+		settled[i].Value = v
+	}
+	return settled
+}
+
+// Any 返回一个在任意输入 Promise 成功时完成的 Promise
+// 如果所有 Promise 都被拒绝，返回一个 vowlink.AggregateError
+func Any[T any](promises ...*Promise[T]) *Promise[T] {
+	return From[T](vl.Any(untyped(promises)...).Then(checked[T], nil))
+}
+
+// Race 返回一个与第一个完成的 Promise 具有相同状态的 Promise
+// 没有输入时与 vowlink.Race 一致，立即以 T 的零值完成
+func Race[T any](promises ...*Promise[T]) *Promise[T] {
+	if len(promises) == 0 {
+		var zero T
+		return Resolve(zero)
+	}
+	return From[T](vl.Race(untyped(promises)...).Then(checked[T], nil))
+}
+
+// 将 Promise[T] 的工厂函数转换为 vowlink 组合函数使用的工厂函数
+func untypedFactory[T, U any](fn func(T) *Promise[U]) func(interface{}) *vl.Promise {
+	return func(value interface{}) *vl.Promise {
+		v, _ := cast[T](value) // 输入来自 []T，类型总是匹配
+		next := fn(v)
+		if next == nil {
+			return nil
+		}
+		return next.p
+	}
+}
+
+// 将 []T 转换为 []interface{}
+func boxed[T any](inputs []T) []interface{} {
+	result := make([]interface{}, len(inputs))
+	for i, input := range inputs {
+		result[i] = input
+	}
+	return result
+}
+
+// MapLimit 对 inputs 中的每个元素调用 fn 创建 Promise，同时最多有 limit 个 Promise 处于执行中
+// 结果 Promise 以按输入顺序排列的 []U 完成，任何一个 Promise 被拒绝时，结果 Promise 立即被拒绝
+func MapLimit[T, U any](inputs []T, limit int, fn func(T) *Promise[U]) *Promise[[]U] {
+	if fn == nil {
+		return nil
+	}
+
+	return From[[]U](vl.MapLimit(boxed(inputs), limit, untypedFactory(fn)).Then(func(value interface{}) (interface{}, error) {
+		return convertSlice[U](value.([]interface{}))
+	}, nil))
+}
+
+// MapLimitSettled 与 MapLimit 类似，但会处理所有输入，按输入顺序返回每个 Promise 的结果
+func MapLimitSettled[T, U any](inputs []T, limit int, fn func(T) *Promise[U]) *Promise[[]Settled[U]] {
+	if fn == nil {
+		return nil
+	}
+
+	return From[[]Settled[U]](vl.MapLimitSettled(boxed(inputs), limit, untypedFactory(fn)).Then(func(value interface{}) (interface{}, error) {
+		return settledResults[U](value.([]vl.SettledResult)), nil
+	}, nil))
+}
+
+// 将以键索引的 Promise[V] 拆分为键和底层 Promise 两个切片，两者位置一一对应
+func splitPromiseMap[K comparable, V any](promises map[K]*Promise[V]) ([]K, []*vl.Promise) {
+	keys := make([]K, 0, len(promises))
+	inputs := make([]*vl.Promise, 0, len(promises))
+	for key, promise := range promises {
+		keys = append(keys, key)
+		inputs = append(inputs, promise.p)
+	}
+	return keys, inputs
+}
+
+// AllMap 等待所有以键索引的 Promise 完成，结果 Promise 的值为 map[K]V
+// 如果任何一个 Promise 被拒绝，结果 Promise 也会被拒绝
+func AllMap[K comparable, V any](promises map[K]*Promise[V]) *Promise[map[K]V] {
+	keys, inputs := splitPromiseMap(promises)
+
+	return From[map[K]V](vl.All(inputs...).Then(func(value interface{}) (interface{}, error) {
+		values, err := convertSlice[V](value.([]interface{}))
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[K]V, len(keys))
+		for i, key := range keys {
+			result[key] = values[i]
+		}
+		return result, nil
+	}, nil))
+}
+
+// AllSettledMap 等待所有以键索引的 Promise 完成，无论其状态如何，结果 Promise 的值为 map[K]Settled[V]
+func AllSettledMap[K comparable, V any](promises map[K]*Promise[V]) *Promise[map[K]Settled[V]] {
+	keys, inputs := splitPromiseMap(promises)
+
+	return From[map[K]Settled[V]](vl.AllSettled(inputs...).Then(func(value interface{}) (interface{}, error) {
+		results := settledResults[V](value.([]vl.SettledResult))
+		settled := make(map[K]Settled[V], len(keys))
+		for i, key := range keys {
+			settled[key] = results[i]
+		}
+		return settled, nil
+	}, nil))
+}
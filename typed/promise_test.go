@@ -0,0 +1,440 @@
+package typed
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	vl "github.com/shengyanli1982/vowlink"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromise_Then(t *testing.T) {
+	t.Run("Fulfilled state", func(t *testing.T) {
+		p := NewPromise(func(resolve func(string), reject func(error)) {
+			resolve("Hello, World!")
+		})
+
+		result := p.Then(func(value string) (string, error) {
+			return value + " vowlink", nil
+		}, nil)
+
+		waitSettled(t, result)
+		assert.Equal(t, "Hello, World! vowlink", result.GetValue(), "Expected value to be 'Hello, World! vowlink'")
+		assert.Nil(t, result.GetReason(), "Expected reason to be nil")
+	})
+
+	t.Run("Rejected state", func(t *testing.T) {
+		p := NewPromise(func(resolve func(string), reject func(error)) {
+			reject(errors.New("Something went wrong"))
+		})
+
+		result := p.Then(nil, func(reason error) (string, error) {
+			return "", errors.New("Handled error: " + reason.Error())
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, "", result.GetValue(), "Expected value to be empty")
+		assert.Equal(t, "Handled error: Something went wrong", result.GetReason().Error(), "Expected reason to be 'Handled error: Something went wrong'")
+	})
+
+	t.Run("Catch recovers value", func(t *testing.T) {
+		result := Reject[int](errors.New("Something went wrong")).Catch(func(reason error) (int, error) {
+			return 42, nil
+		}).Then(func(value int) (int, error) {
+			return value + 1, nil
+		}, nil)
+
+		waitSettled(t, result)
+		assert.Equal(t, 43, result.GetValue(), "Expected value to be 43")
+	})
+
+	t.Run("Finally", func(t *testing.T) {
+		var finallyCalled bool
+		result := Resolve(1).Finally(func() error {
+			finallyCalled = true
+			return nil
+		})
+
+		waitSettled(t, result)
+		assert.True(t, finallyCalled, "Expected finally function to be called")
+		assert.Equal(t, 1, result.GetValue(), "Expected value to be 1")
+	})
+}
+
+func TestMap(t *testing.T) {
+	t.Run("Fulfilled state", func(t *testing.T) {
+		result := Map(Resolve(42), func(value int) (string, error) {
+			return strconv.Itoa(value), nil
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, "42", result.GetValue(), "Expected value to be '42'")
+	})
+
+	t.Run("Rejected state", func(t *testing.T) {
+		result := Map(Reject[int](errors.New("Something went wrong")), func(value int) (string, error) {
+			return strconv.Itoa(value), nil
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, "", result.GetValue(), "Expected value to be empty")
+		assert.Equal(t, "Something went wrong", result.GetReason().Error(), "Expected reason to be 'Something went wrong'")
+	})
+
+	t.Run("Function returns error", func(t *testing.T) {
+		result := Map(Resolve("not a number"), func(value string) (int, error) {
+			return strconv.Atoi(value)
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, 0, result.GetValue(), "Expected value to be 0")
+		assert.NotNil(t, result.GetReason(), "Expected reason to be set")
+	})
+}
+
+func TestFlatMap(t *testing.T) {
+	t.Run("Fulfilled state", func(t *testing.T) {
+		result := FlatMap(Resolve(21), func(value int) *Promise[string] {
+			return Resolve(strconv.Itoa(value * 2))
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, "42", result.GetValue(), "Expected value to be '42'")
+	})
+
+	t.Run("Inner promise rejected", func(t *testing.T) {
+		result := FlatMap(Resolve(21), func(value int) *Promise[string] {
+			return Reject[string](errors.New("Something went wrong"))
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, "Something went wrong", result.GetReason().Error(), "Expected reason to be 'Something went wrong'")
+	})
+
+	t.Run("Inner promise returns nil", func(t *testing.T) {
+		result := FlatMap(Resolve(21), func(value int) *Promise[string] {
+			return nil
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, ErrNilPromise, result.GetReason(), "Expected reason to be ErrNilPromise")
+	})
+
+	t.Run("Inner promise settles asynchronously", func(t *testing.T) {
+		result := FlatMap(Resolve(21), func(value int) *Promise[string] {
+			return NewPromiseAsync(func(resolve func(string), reject func(error)) {
+				resolve(strconv.Itoa(value * 2))
+			})
+		})
+
+		value, err := result.Await(context.Background())
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, "42", value, "Expected value to be '42'")
+	})
+
+	t.Run("Transform function panics", func(t *testing.T) {
+		result := FlatMap(Resolve(21), func(value int) *Promise[string] {
+			panic("boom")
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_, err := result.Await(ctx)
+		var panicErr *vl.PanicError
+		assert.ErrorAs(t, err, &panicErr, "Expected reason to be a PanicError")
+		assert.Equal(t, "boom", panicErr.Value, "Expected panic value to be 'boom'")
+	})
+}
+
+func TestNewPromise(t *testing.T) {
+	assert.Nil(t, NewPromise[int](nil), "Expected nil when handler is nil")
+	assert.Nil(t, NewPromiseAsync[int](nil), "Expected nil when handler is nil")
+	assert.Nil(t, From[int](nil), "Expected nil when promise is nil")
+}
+
+func TestAll(t *testing.T) {
+	t.Run("All promises fulfilled", func(t *testing.T) {
+		result := All(Resolve(1), Resolve(2), Resolve(3))
+
+		waitSettled(t, result)
+		assert.Equal(t, []int{1, 2, 3}, result.GetValue(), "Expected value to be [1, 2, 3]")
+	})
+
+	t.Run("One promise rejected", func(t *testing.T) {
+		result := All(Resolve(1), Reject[int](errors.New("Promise 2 rejected")), Resolve(3))
+
+		waitSettled(t, result)
+		assert.Nil(t, result.GetValue(), "Expected value to be nil")
+		assert.Equal(t, "Promise 2 rejected", result.GetReason().Error(), "Expected reason to be 'Promise 2 rejected'")
+	})
+
+	t.Run("Empty array", func(t *testing.T) {
+		result := All[int]()
+
+		waitSettled(t, result)
+		assert.Equal(t, []int{}, result.GetValue(), "Expected value to be empty")
+	})
+}
+
+func TestAllSettled(t *testing.T) {
+	t.Run("One promise rejected", func(t *testing.T) {
+		reason := errors.New("Promise 2 rejected")
+		result := AllSettled(Resolve("Promise 1"), Reject[string](reason), Resolve("Promise 3"))
+
+		waitSettled(t, result)
+		assert.Equal(t, []Settled[string]{
+			{State: vl.Fulfilled, Value: "Promise 1"},
+			{State: vl.Rejected, Reason: reason},
+			{State: vl.Fulfilled, Value: "Promise 3"},
+		}, result.GetValue(), "Expected settled results in input order")
+	})
+
+	t.Run("Empty array", func(t *testing.T) {
+		result := AllSettled[string]()
+
+		waitSettled(t, result)
+		assert.Equal(t, []Settled[string]{}, result.GetValue(), "Expected value to be empty")
+	})
+}
+
+func TestAny(t *testing.T) {
+	t.Run("One promise fulfilled", func(t *testing.T) {
+		result := Any(Reject[int](errors.New("Promise 1 rejected")), Resolve(2), Resolve(3))
+
+		waitSettled(t, result)
+		assert.Equal(t, 2, result.GetValue(), "Expected value to be 2")
+	})
+
+	t.Run("All promises rejected", func(t *testing.T) {
+		result := Any(Reject[int](errors.New("Promise 1 rejected")), Reject[int](errors.New("Promise 2 rejected")))
+
+		waitSettled(t, result)
+		assert.IsType(t, &vl.AggregateError{}, result.GetReason(), "Expected reason to be an AggregateError")
+	})
+}
+
+func TestRace(t *testing.T) {
+	t.Run("First promise fulfilled", func(t *testing.T) {
+		result := Race(Resolve("Promise 1"), Reject[string](errors.New("Promise 2 rejected")))
+
+		waitSettled(t, result)
+		assert.Equal(t, "Promise 1", result.GetValue(), "Expected value to be 'Promise 1'")
+	})
+
+	t.Run("First promise rejected", func(t *testing.T) {
+		result := Race(Reject[string](errors.New("Promise 1 rejected")), Resolve("Promise 2"))
+
+		waitSettled(t, result)
+		assert.Equal(t, "Promise 1 rejected", result.GetReason().Error(), "Expected reason to be 'Promise 1 rejected'")
+	})
+
+	t.Run("No promises", func(t *testing.T) {
+		value, err := Race[int]().Await(context.Background())
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, 0, value, "Expected value to be 0")
+	})
+}
+
+func TestNewPromiseWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := NewPromiseWithContext(ctx, func(ctx context.Context, resolve func(int), reject func(error)) {})
+	result := Map(p, func(value int) (string, error) {
+		return strconv.Itoa(value), nil
+	})
+
+	cancel()
+
+	value, err := result.Await(context.Background())
+	assert.Equal(t, "", value, "Expected value to be empty")
+	assert.Equal(t, context.Canceled, err, "Expected error to be context.Canceled")
+}
+
+func TestPromise_WithTimeout(t *testing.T) {
+	p := NewPromise(func(resolve func(int), reject func(error)) {})
+
+	value, err := p.WithTimeout(10 * time.Millisecond).Await(context.Background())
+
+	assert.Equal(t, 0, value, "Expected value to be 0")
+	assert.Equal(t, vl.ErrTimeout, err, "Expected error to be ErrTimeout")
+}
+
+func TestPromise_Cancel(t *testing.T) {
+	p := NewPromise(func(resolve func(int), reject func(error)) {})
+	result := AllSettled(p, Resolve(2))
+
+	p.Cancel(nil)
+
+	waitSettled(t, result)
+	assert.Equal(t, []Settled[int]{
+		{State: vl.Cancelled, Reason: vl.ErrCancelled},
+		{State: vl.Fulfilled, Value: 2},
+	}, result.GetValue(), "Expected the first result to be Cancelled")
+}
+
+func TestMapLimit(t *testing.T) {
+	t.Run("Ordered results", func(t *testing.T) {
+		result := MapLimit([]int{1, 2, 3}, 2, func(value int) *Promise[string] {
+			return NewPromiseAsync(func(resolve func(string), reject func(error)) {
+				resolve(strconv.Itoa(value))
+			})
+		})
+
+		value, err := result.Await(context.Background())
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, []string{"1", "2", "3"}, value, "Expected values in input order")
+	})
+
+	t.Run("Settled results", func(t *testing.T) {
+		reason := errors.New("Something went wrong")
+		result := MapLimitSettled([]int{1, 2}, 1, func(value int) *Promise[int] {
+			if value == 2 {
+				return Reject[int](reason)
+			}
+			return Resolve(value)
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, []Settled[int]{
+			{State: vl.Fulfilled, Value: 1},
+			{State: vl.Rejected, Reason: reason},
+		}, result.GetValue(), "Expected settled results in input order")
+	})
+}
+
+func TestAllMap(t *testing.T) {
+	t.Run("All promises fulfilled", func(t *testing.T) {
+		result := AllMap(map[string]*Promise[int]{
+			"a": Resolve(1),
+			"b": Resolve(2),
+		})
+
+		waitSettled(t, result)
+		assert.Equal(t, map[string]int{"a": 1, "b": 2}, result.GetValue(), "Expected values to be keyed by input")
+	})
+
+	t.Run("One promise rejected", func(t *testing.T) {
+		result := AllMap(map[int]*Promise[string]{
+			1: Resolve("a"),
+			2: Reject[string](errors.New("Something went wrong")),
+		})
+
+		waitSettled(t, result)
+		assert.Nil(t, result.GetValue(), "Expected value to be nil")
+		assert.Equal(t, "Something went wrong", result.GetReason().Error(), "Expected reason to be 'Something went wrong'")
+	})
+}
+
+func TestAllSettledMap(t *testing.T) {
+	reason := errors.New("Something went wrong")
+	result := AllSettledMap(map[string]*Promise[int]{
+		"a": Resolve(1),
+		"b": Reject[int](reason),
+	})
+
+	waitSettled(t, result)
+	assert.Equal(t, map[string]Settled[int]{
+		"a": {State: vl.Fulfilled, Value: 1},
+		"b": {State: vl.Rejected, Reason: reason},
+	}, result.GetValue(), "Expected results to be keyed by input")
+}
+
+func TestPromise_State(t *testing.T) {
+	assert.Equal(t, vl.Fulfilled, Resolve(1).State(), "Expected state to be Fulfilled")
+	assert.Equal(t, vl.Rejected, Reject[int](errors.New("Something went wrong")).State(), "Expected state to be Rejected")
+	assert.Equal(t, vl.Pending, NewPromise(func(resolve func(int), reject func(error)) {}).State(), "Expected state to be Pending")
+}
+
+func TestTypeMismatch(t *testing.T) {
+	untypedString := func() *vl.Promise {
+		return vl.NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+			resolve("str", nil)
+		})
+	}
+
+	t.Run("Await", func(t *testing.T) {
+		value, err := From[int](untypedString()).Await(context.Background())
+
+		assert.ErrorIs(t, err, ErrTypeMismatch, "Expected a type mismatch error")
+		assert.Equal(t, "typed: value type mismatch: expected int, got string", err.Error(), "Expected the error to describe both types")
+		assert.Equal(t, 0, value, "Expected value to be zero")
+	})
+
+	t.Run("Then and Map", func(t *testing.T) {
+		var called bool
+		then := From[int](untypedString()).Then(func(value int) (int, error) {
+			called = true
+			return value, nil
+		}, nil)
+		mapped := Map(From[int](untypedString()), func(value int) (string, error) {
+			called = true
+			return strconv.Itoa(value), nil
+		})
+
+		_, thenErr := then.Await(context.Background())
+		_, mapErr := mapped.Await(context.Background())
+		assert.ErrorIs(t, thenErr, ErrTypeMismatch, "Expected Then to be rejected")
+		assert.ErrorIs(t, mapErr, ErrTypeMismatch, "Expected Map to be rejected")
+		assert.False(t, called, "Expected handlers not to be called")
+	})
+
+	t.Run("Combinators", func(t *testing.T) {
+		_, allErr := All(Resolve(1), From[int](untypedString())).Await(context.Background())
+		_, anyErr := Any(From[int](untypedString())).Await(context.Background())
+		settled, _ := AllSettled(Resolve(1), From[int](untypedString())).Await(context.Background())
+
+		assert.ErrorIs(t, allErr, ErrTypeMismatch, "Expected All to be rejected")
+		assert.ErrorIs(t, anyErr, ErrTypeMismatch, "Expected Any to be rejected")
+		assert.Equal(t, vl.Fulfilled, settled[0].State, "Expected the first result to be Fulfilled")
+		assert.Equal(t, vl.Rejected, settled[1].State, "Expected the mismatched result to be Rejected")
+		assert.ErrorIs(t, settled[1].Reason, ErrTypeMismatch, "Expected a type mismatch error")
+	})
+
+	t.Run("Nil value", func(t *testing.T) {
+		untypedNil := func() *vl.Promise {
+			return vl.NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				resolve(nil, nil)
+			})
+		}
+
+		_, intErr := From[int](untypedNil()).Await(context.Background())
+		ptr, ptrErr := From[*int](untypedNil()).Await(context.Background())
+
+		assert.ErrorIs(t, intErr, ErrTypeMismatch, "Expected nil not to match int")
+		assert.Nil(t, ptrErr, "Expected nil to match *int")
+		assert.Nil(t, ptr, "Expected value to be a nil pointer")
+	})
+}
+
+func TestAssimilation(t *testing.T) {
+	result := vl.NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+		resolve(Resolve(42), nil)
+	})
+
+	value, err := result.Await(context.Background())
+	assert.Nil(t, err, "Expected error to be nil")
+	assert.Equal(t, 42, value, "Expected the typed Promise to be followed")
+}
+
+func TestReject_NilReason(t *testing.T) {
+	result := Reject[int](nil)
+
+	assert.Equal(t, vl.Rejected, result.State(), "Expected state to be Rejected")
+	assert.Equal(t, vl.ErrRejectedWithoutReason, result.GetReason(), "Expected reason to be ErrRejectedWithoutReason")
+}
+
+// 等待 Promise 结束，超时时测试失败
+func waitSettled[T any](t *testing.T, p *Promise[T]) {
+	t.Helper()
+
+	select {
+	case <-p.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected the Promise to settle")
+	}
+}
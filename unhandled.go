@@ -0,0 +1,90 @@
+package vowlink
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// UnhandledRejectionHandler 处理没有被任何回调处理的拒绝
+type UnhandledRejectionHandler func(p *Promise, reason error)
+
+// 全局的未处理拒绝处理函数
+var (
+	unhandledMu      sync.RWMutex
+	unhandledHandler UnhandledRejectionHandler
+)
+
+// SetUnhandledRejectionHandler 设置全局的未处理拒绝处理函数，传入 nil 表示关闭
+// 当一个被拒绝的 Promise 在被垃圾回收时，仍然没有通过 Then、Catch、Finally、组合函数、
+// GetReason 或 Await 读取其拒绝原因，处理函数会在终结器所在的 goroutine 中被调用
+// 只有在处理函数设置之后被拒绝的 Promise 才会被追踪
+func SetUnhandledRejectionHandler(handler UnhandledRejectionHandler) {
+	unhandledMu.Lock()
+	defer unhandledMu.Unlock()
+
+	unhandledHandler = handler
+}
+
+func getUnhandledRejectionHandler() UnhandledRejectionHandler {
+	unhandledMu.RLock()
+	defer unhandledMu.RUnlock()
+
+	return unhandledHandler
+}
+
+// OnUnhandledRejection 为当前 Promise 设置未处理拒绝处理函数，优先于全局处理函数
+func (p *Promise) OnUnhandledRejection(handler UnhandledRejectionHandler) *Promise {
+	p.mu.Lock()
+	p.unhandledHandler = handler
+	state, reason := p.state, p.reason
+	p.mu.Unlock()
+
+	if state == Rejected && reason != nil {
+		p.trackUnhandledRejection()
+	}
+
+	return p
+}
+
+// 标记 Promise 的拒绝原因已被处理
+func (p *Promise) markHandled() {
+	atomic.StoreInt32(&p.handled, 1)
+}
+
+// 为尚未被处理的拒绝注册终结器，在 Promise 被回收时检查拒绝是否被处理
+func (p *Promise) trackUnhandledRejection() {
+	if atomic.LoadInt32(&p.handled) == 1 {
+		return
+	}
+
+	p.mu.RLock()
+	handler := p.unhandledHandler
+	p.mu.RUnlock()
+
+	if handler == nil && getUnhandledRejectionHandler() == nil {
+		return
+	}
+
+	if atomic.CompareAndSwapInt32(&p.tracked, 0, 1) {
+		runtime.SetFinalizer(p, reportUnhandledRejection)
+	}
+}
+
+// 终结器：Promise 被回收时仍未处理拒绝，则调用处理函数
+func reportUnhandledRejection(p *Promise) {
+	if atomic.LoadInt32(&p.handled) == 1 {
+		return
+	}
+
+	p.mu.RLock()
+	handler, reason := p.unhandledHandler, p.reason
+	p.mu.RUnlock()
+
+	if handler == nil {
+		handler = getUnhandledRejectionHandler()
+	}
+	if handler != nil {
+		handler(p, reason)
+	}
+}
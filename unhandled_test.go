@@ -0,0 +1,203 @@
+package vowlink
+
+import (
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 反复触发垃圾回收，直到 reported 收到结果或超时
+func waitUnhandledRejection(t *testing.T, reported <-chan error, timeout time.Duration) (error, bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		runtime.GC()
+		select {
+		case reason := <-reported:
+			return reason, true
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return nil, false
+}
+
+func TestPromise_UnhandledRejection(t *testing.T) {
+	t.Run("Global handler reports unhandled rejection", func(t *testing.T) {
+		reported := make(chan error, 1)
+		SetUnhandledRejectionHandler(func(p *Promise, reason error) {
+			reported <- reason
+		})
+		defer SetUnhandledRejectionHandler(nil)
+
+		func() {
+			_ = NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				reject(nil, errors.New("Something went wrong"))
+			})
+		}()
+
+		reason, ok := waitUnhandledRejection(t, reported, time.Second)
+		assert.True(t, ok, "Expected unhandled rejection to be reported")
+		assert.Equal(t, "Something went wrong", reason.Error(), "Expected reason to be 'Something went wrong'")
+	})
+
+	t.Run("Rejection at the end of a chain is reported", func(t *testing.T) {
+		reported := make(chan error, 1)
+		SetUnhandledRejectionHandler(func(p *Promise, reason error) {
+			reported <- reason
+		})
+		defer SetUnhandledRejectionHandler(nil)
+
+		func() {
+			_ = NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				reject(nil, errors.New("Something went wrong"))
+			}).Catch(func(reason error) (interface{}, error) {
+				return nil, errors.New("Handled error: " + reason.Error())
+			})
+		}()
+
+		reason, ok := waitUnhandledRejection(t, reported, time.Second)
+		assert.True(t, ok, "Expected unhandled rejection to be reported")
+		assert.Equal(t, "Handled error: Something went wrong", reason.Error(), "Expected reason to be 'Handled error: Something went wrong'")
+	})
+
+	t.Run("Dropped Group rejection is reported", func(t *testing.T) {
+		reported := make(chan error, 1)
+		SetUnhandledRejectionHandler(func(p *Promise, reason error) {
+			reported <- reason
+		})
+		defer SetUnhandledRejectionHandler(nil)
+
+		var g Group
+		func() {
+			_ = g.Do("key", func() *Promise {
+				return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+					reject(nil, errors.New("Something went wrong"))
+				})
+			})
+		}()
+
+		reason, ok := waitUnhandledRejection(t, reported, time.Second)
+		assert.True(t, ok, "Expected unhandled rejection to be reported")
+		assert.Equal(t, "Something went wrong", reason.Error(), "Expected reason to be 'Something went wrong'")
+	})
+
+	t.Run("Dropped PromiseCache rejection is reported", func(t *testing.T) {
+		reported := make(chan error, 1)
+		SetUnhandledRejectionHandler(func(p *Promise, reason error) {
+			reported <- reason
+		})
+		defer SetUnhandledRejectionHandler(nil)
+
+		cache := NewPromiseCache()
+		func() {
+			_ = cache.Get("key", func() *Promise {
+				return NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+					reject(nil, errors.New("Something went wrong"))
+				})
+			})
+		}()
+
+		reason, ok := waitUnhandledRejection(t, reported, time.Second)
+		assert.True(t, ok, "Expected unhandled rejection to be reported")
+		assert.Equal(t, "Something went wrong", reason.Error(), "Expected reason to be 'Something went wrong'")
+	})
+
+	t.Run("Failed PromiseCache refresh is not reported", func(t *testing.T) {
+		reported := make(chan error, 1)
+		SetUnhandledRejectionHandler(func(p *Promise, reason error) {
+			reported <- reason
+		})
+		defer SetUnhandledRejectionHandler(nil)
+
+		clock := newTestClock()
+		cache := NewPromiseCache(WithCacheTTL(time.Minute), WithStaleWhileRevalidate(time.Minute), WithCacheClock(clock.Now))
+		var calls int32
+		factory := makeCacheFactory(&calls, func(n int32) bool { return n == 2 })
+
+		func() {
+			_ = cache.Get("key", factory)
+			clock.Advance(90 * time.Second)
+			_ = cache.Get("key", factory)
+		}()
+		waitCacheRefreshed(t, cache, "key")
+
+		_, ok := waitUnhandledRejection(t, reported, 200*time.Millisecond)
+		assert.False(t, ok, "Expected the failed refresh not to be reported")
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "Expected the refresh to have been attempted")
+	})
+
+	t.Run("Per-promise handler", func(t *testing.T) {
+		reported := make(chan error, 1)
+
+		func() {
+			_ = NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				reject(nil, errors.New("Something went wrong"))
+			}).OnUnhandledRejection(func(p *Promise, reason error) {
+				reported <- reason
+			})
+		}()
+
+		reason, ok := waitUnhandledRejection(t, reported, time.Second)
+		assert.True(t, ok, "Expected unhandled rejection to be reported")
+		assert.Equal(t, "Something went wrong", reason.Error(), "Expected reason to be 'Something went wrong'")
+	})
+
+	t.Run("Handled rejections are not reported", func(t *testing.T) {
+		reported := make(chan error, 3)
+		SetUnhandledRejectionHandler(func(p *Promise, reason error) {
+			reported <- reason
+		})
+		defer SetUnhandledRejectionHandler(nil)
+
+		func() {
+			_ = NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				reject(nil, errors.New("caught"))
+			}).Catch(func(reason error) (interface{}, error) {
+				return "Recovered value", nil
+			})
+
+			_ = NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				reject(nil, errors.New("read"))
+			}).GetReason()
+
+			_ = AllSettled(NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				reject(nil, errors.New("settled"))
+			}))
+		}()
+
+		_, ok := waitUnhandledRejection(t, reported, 200*time.Millisecond)
+		assert.False(t, ok, "Expected no unhandled rejection to be reported")
+	})
+
+	t.Run("Inputs of Some are handled when it settles early", func(t *testing.T) {
+		reported := make(chan error, 2)
+		SetUnhandledRejectionHandler(func(p *Promise, reason error) {
+			reported <- reason
+		})
+		defer SetUnhandledRejectionHandler(nil)
+
+		func() {
+			var rejectFirst, rejectSecond func(interface{}, error)
+			first := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				rejectFirst = reject
+			})
+			second := NewPromise(func(resolve func(interface{}, error), reject func(interface{}, error)) {
+				rejectSecond = reject
+			})
+
+			_ = Some(2, first).GetReason()
+			_ = Some(0, second)
+
+			rejectFirst(nil, errors.New("first"))
+			rejectSecond(nil, errors.New("second"))
+		}()
+
+		_, ok := waitUnhandledRejection(t, reported, 200*time.Millisecond)
+		assert.False(t, ok, "Expected no unhandled rejection to be reported")
+	})
+}